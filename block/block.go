@@ -1,6 +1,7 @@
 package block
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/AevtJJ/idmangler/encoding"
@@ -83,6 +84,11 @@ func (e *InvalidBlockIDError) Error() string {
 	return fmt.Sprintf("Invalid block id: %d", e.ID)
 }
 
+// Unwrap returns encoding.ErrUnknownBlock, allowing the error to be matched with errors.Is
+func (e *InvalidBlockIDError) Unwrap() error {
+	return encoding.ErrUnknownBlock
+}
+
 // DataBlockIDFromByte converts a byte to a DataBlockID or returns an error if invalid
 func DataBlockIDFromByte(b byte) (DataBlockID, error) {
 	switch DataBlockID(b) {
@@ -108,7 +114,8 @@ type DataEncoder interface {
 
 // DataDecoder defines the interface for decoding data blocks
 type DataDecoder interface {
-	// DecodeData decodes data for this block from the given bytes
+	// DecodeData decodes data for this block from the given bytes and returns the number of bytes used.
+	// On failure the returned number is the offset of the field that failed to decode.
 	DecodeData(bytes []byte, ver types.EncodingVersion) (int, error)
 }
 
@@ -127,11 +134,30 @@ type AnyBlock interface {
 	AsID() DataBlockID
 }
 
-// DecodeBlock decodes a single block from the given byte stream
+// decodableBlock represents a block that can decode its own data
+type decodableBlock interface {
+	AnyBlock
+	DataDecoder
+}
+
+// limitedDecoder is implemented by blocks whose decoding is bounded by DecodeOptions
+type limitedDecoder interface {
+	decodeDataWith(bytes []byte, ver types.EncodingVersion, opts *DecodeOptions) (int, error)
+}
+
+// DecodeBlock decodes a single block from the given byte stream without resource limits.
+// Errors are returned as an *encoding.DecoderError with the offset relative to the start of the given bytes.
 func DecodeBlock(ver types.EncodingVersion, bytes []byte) (AnyBlock, int, error) {
+	return decodeBlock(ver, bytes, nil)
+}
+
+// decodeBlock decodes a single block from the given byte stream within the given limits, nil for no limits
+func decodeBlock(ver types.EncodingVersion, bytes []byte, opts *DecodeOptions) (AnyBlock, int, error) {
+	// Both errors before the block is known happen at its first byte
 	if len(bytes) == 0 {
 		return nil, 0, &encoding.DecoderError{
 			ErrorData: &encoding.DecodeError{Type: encoding.ErrUnexpectedEndOfBytes},
+			Offset:    0,
 		}
 	}
 
@@ -140,145 +166,260 @@ func DecodeBlock(ver types.EncodingVersion, bytes []byte) (AnyBlock, int, error)
 	if err != nil {
 		return nil, 0, &encoding.DecoderError{
 			ErrorData: &encoding.DecodeError{
-				Type:    encoding.ErrUnknownBlock,
-				Details: err,
+				Type: encoding.ErrUnknownBlock,
+				Err:  err,
 			},
+			Offset: 0,
 		}
 	}
 
-	// Move past the ID byte
-	bytesUsed := 1
-	remainingBytes := bytes[bytesUsed:]
-
-	// Decode the block based on the ID
-	var block AnyBlock
-	var blockBytesUsed int
+	// Create an empty block based on the ID
+	var block decodableBlock
 
 	switch id {
 	case BlockStartData:
 		// StartData should not be decoded after the beginning
-		return nil, 0, &encoding.DecoderError{
-			ErrorData: &encoding.DecodeError{Type: encoding.ErrStartReparse},
-			During:    (*encoding.DataBlockID)(&id),
-		}
-
+		return nil, 0, newBlockError(id, 0, &encoding.DecodeError{Type: encoding.ErrStartReparse})
 	case BlockTypeData:
-		typeData := &TypeData{}
-		n, err := typeData.DecodeData(remainingBytes, ver)
-		if err != nil {
-			return nil, 0, &encoding.DecoderError{
-				ErrorData: err.(*encoding.DecodeError),
-				During:    (*encoding.DataBlockID)(&id),
-			}
-		}
-		block = typeData
-		blockBytesUsed = n
-
+		block = &TypeData{}
 	case BlockNameData:
-		nameData := &NameData{}
-		n, err := nameData.DecodeData(remainingBytes, ver)
-		if err != nil {
-			return nil, 0, &encoding.DecoderError{
-				ErrorData: err.(*encoding.DecodeError),
-				During:    (*encoding.DataBlockID)(&id),
-			}
-		}
-		block = nameData
-		blockBytesUsed = n
-
+		block = &NameData{}
 	case BlockIdentificationData:
-		identData := &IdentificationData{}
-		n, err := identData.DecodeData(remainingBytes, ver)
-		if err != nil {
-			return nil, 0, &encoding.DecoderError{
-				ErrorData: err.(*encoding.DecodeError),
-				During:    (*encoding.DataBlockID)(&id),
-			}
-		}
-		block = identData
-		blockBytesUsed = n
-
+		block = &IdentificationData{}
 	case BlockPowderData:
-		powderData := &PowderData{}
-		n, err := powderData.DecodeData(remainingBytes, ver)
-		if err != nil {
-			return nil, 0, &encoding.DecoderError{
-				ErrorData: err.(*encoding.DecodeError),
-				During:    (*encoding.DataBlockID)(&id),
-			}
-		}
-		block = powderData
-		blockBytesUsed = n
-
+		block = &PowderData{}
 	case BlockRerollData:
-		rerollData := &RerollData{}
-		n, err := rerollData.DecodeData(remainingBytes, ver)
-		if err != nil {
-			return nil, 0, &encoding.DecoderError{
-				ErrorData: err.(*encoding.DecodeError),
-				During:    (*encoding.DataBlockID)(&id),
-			}
-		}
-		block = rerollData
-		blockBytesUsed = n
-
+		block = &RerollData{}
 	case BlockShinyData:
-		shinyData := &ShinyData{}
-		n, err := shinyData.DecodeData(remainingBytes, ver)
-		if err != nil {
-			return nil, 0, &encoding.DecoderError{
-				ErrorData: err.(*encoding.DecodeError),
-				During:    (*encoding.DataBlockID)(&id),
-			}
-		}
-		block = shinyData
-		blockBytesUsed = n
-
+		block = &ShinyData{}
+	case BlockCraftedGearType:
+		block = &CraftedGearTypeData{}
+	case BlockDurabilityData:
+		block = &DurabilityData{}
+	case BlockRequirementsData:
+		block = &RequirementsData{}
+	case BlockDamageData:
+		block = &DamageData{}
+	case BlockDefenseData:
+		block = &DefenseData{}
+	case BlockCraftedIdentificationData:
+		block = &CraftedIdentificationData{}
+	case BlockCraftedConsumableTypeData:
+		block = &CraftedConsumableTypeData{}
+	case BlockUsesData:
+		block = &UsesData{}
+	case BlockEffectsData:
+		block = &EffectsData{}
 	case BlockEndData:
-		endData := &EndData{}
-		n, err := endData.DecodeData(remainingBytes, ver)
-		if err != nil {
-			return nil, 0, &encoding.DecoderError{
-				ErrorData: err.(*encoding.DecodeError),
-				During:    (*encoding.DataBlockID)(&id),
-			}
-		}
-		block = endData
-		blockBytesUsed = n
-
+		block = &EndData{}
 	default:
 		// Other block types not yet implemented
-		return nil, 0, &encoding.DecoderError{
-			ErrorData: &encoding.DecodeError{
-				Type:    encoding.ErrUnknownBlock,
-				Details: id,
-			},
-			During: (*encoding.DataBlockID)(&id),
+		return nil, 0, newBlockError(id, 0, &encoding.DecodeError{
+			Type: encoding.ErrUnknownBlock,
+			Err:  &InvalidBlockIDError{ID: byte(id)},
+		})
+	}
+
+	// Decode the data following the ID byte.
+	// On failure the number of bytes returned is the offset of the field that failed to decode.
+	var n int
+	if limited, ok := block.(limitedDecoder); ok {
+		n, err = limited.decodeDataWith(bytes[1:], ver, opts)
+	} else {
+		n, err = block.DecodeData(bytes[1:], ver)
+	}
+	if err != nil {
+		return nil, 0, newBlockError(id, 1+n, err)
+	}
+
+	return block, 1 + n, nil
+}
+
+// newBlockError wraps an error that happened while decoding a block with the block and the offset of the failure
+func newBlockError(id DataBlockID, offset int, err error) *encoding.DecoderError {
+	var decodeErr *encoding.DecodeError
+	if !errors.As(err, &decodeErr) {
+		decodeErr = &encoding.DecodeError{
+			Type: encoding.ErrBadBlockData,
+			Err:  err,
 		}
 	}
 
-	return block, bytesUsed + blockBytesUsed, nil
+	during := encoding.DataBlockID(id)
+	return &encoding.DecoderError{
+		ErrorData: decodeErr,
+		During:    &during,
+		Block:     id.String(),
+		Offset:    offset,
+	}
+}
+
+// duplicateError describes a value that appears more than once where it may appear only once
+func duplicateError(name string, value int64) error {
+	return &encoding.ValueError{
+		Name:   name,
+		Value:  value,
+		Reason: "appears more than once",
+	}
+}
+
+// outOfOrderError describes an entry which is encoded after an entry it has to precede
+func outOfOrderError(name string, value int64) error {
+	return &encoding.ValueError{
+		Name:   name,
+		Value:  value,
+		Reason: "is out of order",
+	}
+}
+
+// shiftError moves the offset of a decoder error by the given number of bytes
+func shiftError(err error, n int) error {
+	var decoderErr *encoding.DecoderError
+	if errors.As(err, &decoderErr) && decoderErr.Offset >= 0 {
+		decoderErr.Offset += n
+	}
+	return err
 }
 
-// DecodeAllBlocks decodes all blocks from the given byte stream until the end block
+// DecodeAllBlocks decodes all blocks from the given byte stream until the end block without any limits
 func DecodeAllBlocks(ver types.EncodingVersion, bytes []byte) ([]AnyBlock, error) {
+	return DecodeAllBlocksWithOptions(ver, bytes, DecodeOptions{})
+}
+
+// DecodeAllBlocksWithOptions decodes all blocks from the given byte stream until the end block
+// within the given limits. Use DefaultDecodeOptions for untrusted input.
+func DecodeAllBlocksWithOptions(ver types.EncodingVersion, bytes []byte, opts DecodeOptions) ([]AnyBlock, error) {
+	return NewItemDecoder().WithOptions(opts).decodeBlocks(ver, bytes)
+}
+
+// decodeBlocks decodes all blocks from the given byte stream until the end block within the limits of the decoder.
+// If unknown blocks are preserved, an unknown block and everything after it is kept as a RawTailBlock.
+// In strict mode trailing bytes, a missing end block and duplicated singleton blocks are rejected.
+func (d *ItemDecoder) decodeBlocks(ver types.EncodingVersion, bytes []byte) ([]AnyBlock, error) {
 	blocks := make([]AnyBlock, 0)
+	_, _, err := d.walkBlocks(ver, bytes, func(block AnyBlock, offset int) bool {
+		blocks = append(blocks, block)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+// walkBlocks decodes blocks like decodeBlocks, passing each block and its byte offset to visit as soon as it is decoded.
+// Returns the number of bytes used, which is the offset of the failing block on error,
+// and true if visit returned false to stop decoding.
+func (d *ItemDecoder) walkBlocks(ver types.EncodingVersion, bytes []byte, visit func(block AnyBlock, offset int) bool) (int, bool, error) {
 	bytesUsed := 0
+	count := 0
+	ended := false
+	seen := make(map[DataBlockID]bool)
+	var schema *ItemSchema
 
 	for bytesUsed < len(bytes) {
-		block, n, err := DecodeBlock(ver, bytes[bytesUsed:])
+		if err := d.options.checkBlocks(count + 1); err != nil {
+			return bytesUsed, false, limitDecoderError(err, bytesUsed)
+		}
+
+		// Keep unknown blocks verbatim if requested
+		if d.preserveUnknown {
+			if tail, n, ok := decodeRawTail(ver, bytes[bytesUsed:]); ok {
+				if !visit(tail, bytesUsed) {
+					return bytesUsed, true, nil
+				}
+				bytesUsed += n
+				ended = true
+				break
+			}
+		}
+
+		block, n, err := decodeBlock(ver, bytes[bytesUsed:], &d.options)
 		if err != nil {
-			return nil, err
+			return bytesUsed, false, shiftError(err, bytesUsed)
 		}
 
-		blocks = append(blocks, block)
+		// Reject a second occurrence of a block which may only appear once
+		if d.strict {
+			if typeData, ok := block.(*TypeData); ok && schema == nil {
+				if itemSchema, ok := SchemaFor(typeData.ItemType); ok {
+					schema = &itemSchema
+				}
+			}
+			if seen[block.AsID()] && !repeatable(schema, block.AsID()) {
+				return bytesUsed, false, newBlockError(block.AsID(), bytesUsed, &encoding.DecodeError{
+					Type: encoding.ErrDuplicateBlock,
+					Err:  &DuplicateBlockError{Block: block.AsID()},
+				})
+			}
+			seen[block.AsID()] = true
+		}
+
+		if !visit(block, bytesUsed) {
+			return bytesUsed, true, nil
+		}
+		count++
 		bytesUsed += n
 
 		// If we reached the end block, stop
 		if block.AsID() == BlockEndData {
+			ended = true
 			break
 		}
 	}
 
-	return blocks, nil
+	if d.strict {
+		if !ended {
+			return bytesUsed, false, &encoding.DecoderError{
+				ErrorData: &encoding.DecodeError{Type: encoding.ErrMissingEndData},
+				Offset:    len(bytes),
+			}
+		}
+		if bytesUsed < len(bytes) {
+			return bytesUsed, false, &encoding.DecoderError{
+				ErrorData: &encoding.DecodeError{
+					Type: encoding.ErrTrailingBytes,
+					Err:  trailingBytesError(len(bytes) - bytesUsed),
+				},
+				Offset: bytesUsed,
+			}
+		}
+	}
+
+	return bytesUsed, false, nil
+}
+
+// DuplicateBlockError represents a block which may only appear once but was found again
+type DuplicateBlockError struct {
+	// Block is the ID of the duplicated block
+	Block DataBlockID
+}
+
+// Error returns the error message for a duplicated block
+func (e *DuplicateBlockError) Error() string {
+	return fmt.Sprintf("%s may only appear once", e.Block)
+}
+
+// CheckDuplicates returns a *DuplicateBlockError for the first block which appears again
+// although the schema of the item type allows it only once
+func CheckDuplicates(blocks []AnyBlock) error {
+	schema := schemaForBlocks(blocks)
+	seen := make(map[DataBlockID]bool)
+	for _, b := range blocks {
+		if seen[b.AsID()] && !repeatable(schema, b.AsID()) {
+			return &DuplicateBlockError{Block: b.AsID()}
+		}
+		seen[b.AsID()] = true
+	}
+	return nil
+}
+
+// trailingBytesError describes bytes following the end block
+func trailingBytesError(n int) error {
+	return &encoding.ValueError{
+		Name:   "EndData followed by",
+		Value:  int64(n),
+		Reason: "trailing bytes",
+	}
 }
@@ -0,0 +1,195 @@
+package block
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+func TestDecodeErrorOffsets(t *testing.T) {
+	testCases := []struct {
+		name   string
+		bytes  []byte
+		kind   encoding.ErrorKind
+		block  string
+		offset int
+	}{
+		{"no start", []byte{1, 0}, encoding.ErrNoStartBlockFound, "StartData", 0},
+		{"bad version", []byte{0, 9}, encoding.ErrUnknownVersion, "StartData", 0},
+		{"bad item type", []byte{0, 1, 1, 9}, encoding.ErrBadItemType, "TypeData", 3},
+		{"unknown block", []byte{0, 1, 1, 0, 99}, encoding.ErrUnknownBlock, "", 4},
+		{"unterminated name", []byte{0, 1, 1, 0, 2, 'A', 'B'}, encoding.ErrUnexpectedEndOfBytes, "NameData", 7},
+		{"bad skill", []byte{0, 1, 1, 3, 9, 10, 0, 2, 0, 2, 7, 5}, encoding.ErrBadSkillType, "RequirementsData", 10},
+		{"bad uses", []byte{0, 1, 1, 4, 14, 3, 2}, encoding.ErrInvalidUses, "UsesData", 5},
+		{"base out of range", append([]byte{0, 1, 1, 0, 3, 1, 1, 0, 7}, encoding.EncodeVarInt(1<<31)...), encoding.ErrInvalidVarInt, "IdentificationData", 9},
+		{"non ascii name", []byte{0, 1, 1, 0, 2, 'A', 200, 0, 255}, encoding.ErrNonAsciiString, "NameData", 6},
+		{"non canonical varint", []byte{0, 1, 1, 0, 6, 1, 0x82, 0x00, 255}, encoding.ErrInvalidVarInt, "ShinyData", 6},
+		{"defenses out of order", []byte{0, 1, 1, 3, 11, 0, 2, 4, 2, 0, 2}, encoding.ErrNonCanonical, "DefenseData", 9},
+		{"neutral damage last", []byte{0, 1, 1, 3, 10, 1, 2, 1, 2, 4, 5, 2, 4}, encoding.ErrNonCanonical, "DamageData", 10},
+		{"empty powder", []byte{0, 1, 1, 0, 4, 4, 2, 0b00110_000, 0}, encoding.ErrNonCanonical, "PowderData", 7},
+		{"powder padding", []byte{0, 1, 1, 0, 4, 4, 1, 0b00110_001}, encoding.ErrNonCanonical, "PowderData", 7},
+	}
+
+	for _, tc := range testCases {
+		_, err := NewItemDecoder().DecodeString(encoding.EncodeString(tc.bytes))
+		if !errors.Is(err, tc.kind) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.kind, err)
+			continue
+		}
+
+		var decoderErr *encoding.DecoderError
+		if !errors.As(err, &decoderErr) {
+			t.Errorf("%s: expected a DecoderError, got %T", tc.name, err)
+			continue
+		}
+
+		if decoderErr.Block != tc.block {
+			t.Errorf("%s: expected block %q, got %q", tc.name, tc.block, decoderErr.Block)
+		}
+
+		if decoderErr.Offset != tc.offset || decoderErr.Codepoint() != tc.offset/2 {
+			t.Errorf("%s: expected offset %d, got %d (codepoint %d)", tc.name, tc.offset, decoderErr.Offset, decoderErr.Codepoint())
+		}
+	}
+}
+
+func TestDecodeErrorDetails(t *testing.T) {
+	_, _, err := DecodeBlock(types.Version1, []byte{byte(BlockCraftedGearType), 42})
+
+	var badGearType *types.BadGearTypeError
+	if !errors.As(err, &badGearType) || badGearType.ID != 42 {
+		t.Errorf("Expected a BadGearTypeError for id 42, got %v", err)
+	}
+
+	_, err = NewItemDecoder().DecodeString("not an id string")
+	var badCodepoint *encoding.BadCodepointError
+	if !errors.Is(err, encoding.ErrBadCodepoint) || !errors.As(err, &badCodepoint) || badCodepoint.Index != 0 {
+		t.Errorf("Expected a BadCodepointError at index 0, got %v", err)
+	}
+}
+
+// seedItems returns the decoded data of real items for the fuzz corpus
+func seedItems(tb testing.TB) [][]byte {
+	class := types.Warrior
+	neutral := types.NewElementalRange(10, 20)
+	items := [][]AnyBlock{
+		{
+			NewStartData(types.Version1),
+			NewTypeData(types.Gear),
+			NewNameData("Aftershock"),
+			NewIdentificationData([]*types.Stat{
+				types.NewStat(56, nil, types.NewValueRoll(93)),
+				types.NewStat(9, nil, types.NewValueRoll(108)),
+			}, false),
+			NewPowderData(4, []types.Powder{{Element: types.Earth, Tier: 6}, {Element: types.Air, Tier: 3}}),
+			NewRerollData(2),
+			NewShinyData(3, 1200),
+		},
+		{
+			&StartData{Version: types.Version1, Legacy: true},
+			NewTypeData(types.CraftedGear),
+			NewCraftedGearTypeData(types.Spear),
+			NewDurabilityData(95, 120, 100),
+			NewRequirementsData(60, &class, []types.SkillRequirement{{Skill: types.Strength, Points: 30}}),
+			NewDamageData(types.Fast, &neutral, map[types.Element]types.ElementalRange{types.Fire: types.NewElementalRange(5, 9)}),
+			NewDefenseData(-120, map[types.Element]int32{types.Water: -15}),
+			NewCraftedIdentificationData([]*types.CraftedStat{types.NewCraftedStat(3, 42)}),
+			NewNameData("Crafted Spear"),
+		},
+		{
+			NewStartData(types.Version1),
+			NewTypeData(types.CraftedConsu),
+			NewCraftedConsumableTypeData(types.Potion),
+			NewUsesData(2, 3),
+			NewEffectsData([]types.Effect{{Kind: types.Heal, Value: 420}}),
+			NewRequirementsData(45, nil, []types.SkillRequirement{}),
+		},
+	}
+
+	seeds := make([][]byte, 0, len(items))
+	for _, blocks := range items {
+		var bytes []byte
+		for _, b := range append(blocks, NewEndData()) {
+			if err := b.Encode(types.Version1, &bytes); err != nil {
+				tb.Fatalf("Error encoding seed %v: %v", b, err)
+			}
+		}
+		seeds = append(seeds, bytes)
+	}
+
+	return seeds
+}
+
+func FuzzDecodeAllBlocks(f *testing.F) {
+	for _, seed := range seedItems(f) {
+		f.Add(seed)
+	}
+	f.Add([]byte{0, 1, 3, 255, 1, 0})
+	f.Add([]byte{0, 1, 4, 6, 255, 0xFF, 0xFF})
+
+	decoders := []*ItemDecoder{
+		NewItemDecoder(),
+		NewItemDecoder().WithStrict(true).WithUnknownBlocks(true),
+		NewItemDecoder().WithSchemaValidation(false).WithOptions(DecodeOptions{LenientVarInts: true}),
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if blocks, err := DecodeAllBlocks(types.Version1, data); err == nil {
+			reencode(t, blocks)
+		}
+
+		idString := encoding.EncodeString(data)
+		_ = Inspect(idString).String()
+		for _, decoder := range decoders {
+			blocks, err := decoder.DecodeString(idString)
+			if err == nil {
+				reencode(t, blocks)
+			}
+
+			if result := decoder.DecodeStringLenient(idString); result.Err == nil {
+				reencode(t, result.Blocks)
+			}
+		}
+	})
+}
+
+// reencode encodes decoded blocks, which must not fail
+func reencode(t *testing.T, blocks []AnyBlock) {
+	var bytes []byte
+	for _, b := range blocks {
+		if err := b.Encode(types.Version1, &bytes); err != nil {
+			t.Errorf("Error re-encoding decoded %T: %v", b, err)
+		}
+	}
+}
+
+// roundTripBlock encodes a block, decodes it again and checks that nothing changed.
+// Returns the encoded bytes including the block ID.
+func roundTripBlock(t *testing.T, block AnyBlock) []byte {
+	t.Helper()
+
+	var bytes []byte
+	if err := block.Encode(types.Version1, &bytes); err != nil {
+		t.Errorf("Error encoding %+v: %v", block, err)
+		return nil
+	}
+
+	decoded, n, err := DecodeBlock(types.Version1, bytes)
+	if err != nil {
+		t.Errorf("Error decoding %+v: %v", block, err)
+		return bytes
+	}
+
+	if n != len(bytes) {
+		t.Errorf("Incorrect number of bytes used. Expected %d, got %d", len(bytes), n)
+	}
+
+	if !reflect.DeepEqual(decoded, block) {
+		t.Errorf("Block mismatch after round trip. Expected %+v, got %+v", block, decoded)
+	}
+
+	return bytes
+}
@@ -0,0 +1,77 @@
+package block
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+func TestConsumableBlocksRoundTrip(t *testing.T) {
+	testCases := []AnyBlock{
+		NewCraftedConsumableTypeData(types.Potion),
+		NewCraftedConsumableTypeData(types.Food),
+		NewCraftedConsumableTypeData(types.Scroll),
+		NewUsesData(0, 0),
+		NewUsesData(2, 3),
+		NewUsesData(255, 255),
+		NewEffectsData([]types.Effect{}),
+		NewEffectsData([]types.Effect{
+			{Kind: types.Heal, Value: 1500},
+			{Kind: types.Mana, Value: -20},
+			{Kind: types.Duration, Value: 180},
+		}),
+	}
+
+	for _, tc := range testCases {
+		roundTripBlock(t, tc)
+	}
+}
+
+func TestConsumableBlocksInvalid(t *testing.T) {
+	testCases := []struct {
+		bytes []byte
+		kind  encoding.ErrorKind
+	}{
+		{[]byte{byte(BlockCraftedConsumableTypeData), 3}, encoding.ErrBadConsumableType},
+		{[]byte{byte(BlockUsesData), 4, 3}, encoding.ErrInvalidUses},
+		{[]byte{byte(BlockUsesData), 1}, encoding.ErrUnexpectedEndOfBytes},
+		{[]byte{byte(BlockEffectsData), 1, 3, 0}, encoding.ErrBadEffectType},
+		{[]byte{byte(BlockEffectsData), 2, 0, 2}, encoding.ErrUnexpectedEndOfBytes},
+	}
+
+	for _, tc := range testCases {
+		if _, _, err := DecodeBlock(types.Version1, tc.bytes); !errors.Is(err, tc.kind) {
+			t.Errorf("Expected %v when decoding %v, got %v", tc.kind, tc.bytes, err)
+		}
+	}
+}
+
+func TestCraftedConsumableItemRoundTrip(t *testing.T) {
+	blocks := []AnyBlock{
+		NewStartData(types.Version1),
+		NewTypeData(types.CraftedConsu),
+		NewCraftedConsumableTypeData(types.Potion),
+		NewUsesData(3, 3),
+		NewEffectsData([]types.Effect{{Kind: types.Heal, Value: 420}}),
+		NewRequirementsData(45, nil, []types.SkillRequirement{}),
+		NewNameData("Crafted Potion"),
+		NewEndData(),
+	}
+
+	idString, err := NewItemEncoder().EncodeBlocks(blocks)
+	if err != nil {
+		t.Fatalf("Error encoding item: %v", err)
+	}
+
+	decoded, err := NewItemDecoder().DecodeString(idString)
+	if err != nil {
+		t.Fatalf("Error decoding item: %v", err)
+	}
+
+	if !reflect.DeepEqual(decoded, blocks) {
+		t.Errorf("Blocks mismatch after round trip. Expected %+v, got %+v", blocks, decoded)
+	}
+}
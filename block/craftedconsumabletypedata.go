@@ -0,0 +1,67 @@
+package block
+
+import (
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+// CraftedConsumableTypeData represents the consumable type block of a crafted consumable in an encoded item string.
+type CraftedConsumableTypeData struct {
+	ConsumableType types.ConsumableType
+}
+
+// BlockID returns the ID of this block
+func (c *CraftedConsumableTypeData) BlockID() DataBlockID {
+	return BlockCraftedConsumableTypeData
+}
+
+// AsID returns the ID of this block
+func (c *CraftedConsumableTypeData) AsID() DataBlockID {
+	return c.BlockID()
+}
+
+// EncodeData encodes this block's data into the given output buffer
+func (c *CraftedConsumableTypeData) EncodeData(ver types.EncodingVersion, out *[]byte) error {
+	if _, err := types.ConsumableTypeFromByte(byte(c.ConsumableType)); err != nil {
+		return &encoding.EncodeError{
+			Type: encoding.ErrBadConsumableType,
+			Err:  err,
+		}
+	}
+
+	*out = append(*out, byte(c.ConsumableType))
+	return nil
+}
+
+// Encode encodes this block with its ID into the given output buffer
+func (c *CraftedConsumableTypeData) Encode(ver types.EncodingVersion, out *[]byte) error {
+	// Write block ID
+	*out = append(*out, byte(c.BlockID()))
+	// Write block data
+	return c.EncodeData(ver, out)
+}
+
+// DecodeData decodes data for this block from the given bytes
+func (c *CraftedConsumableTypeData) DecodeData(bytes []byte, ver types.EncodingVersion) (int, error) {
+	if len(bytes) < 1 {
+		return 0, &encoding.DecodeError{Type: encoding.ErrUnexpectedEndOfBytes}
+	}
+
+	consumableType, err := types.ConsumableTypeFromByte(bytes[0])
+	if err != nil {
+		return 0, &encoding.DecodeError{
+			Type: encoding.ErrBadConsumableType,
+			Err:  err,
+		}
+	}
+
+	c.ConsumableType = consumableType
+	return 1, nil
+}
+
+// NewCraftedConsumableTypeData creates a new CraftedConsumableTypeData block with the specified consumable type
+func NewCraftedConsumableTypeData(consumableType types.ConsumableType) *CraftedConsumableTypeData {
+	return &CraftedConsumableTypeData{
+		ConsumableType: consumableType,
+	}
+}
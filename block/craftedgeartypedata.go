@@ -0,0 +1,67 @@
+package block
+
+import (
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+// CraftedGearTypeData represents the gear type block of a crafted item in an encoded item string.
+type CraftedGearTypeData struct {
+	GearType types.CraftedGearType
+}
+
+// BlockID returns the ID of this block
+func (c *CraftedGearTypeData) BlockID() DataBlockID {
+	return BlockCraftedGearType
+}
+
+// AsID returns the ID of this block
+func (c *CraftedGearTypeData) AsID() DataBlockID {
+	return c.BlockID()
+}
+
+// EncodeData encodes this block's data into the given output buffer
+func (c *CraftedGearTypeData) EncodeData(ver types.EncodingVersion, out *[]byte) error {
+	if _, err := types.CraftedGearTypeFromByte(byte(c.GearType)); err != nil {
+		return &encoding.EncodeError{
+			Type: encoding.ErrBadGearType,
+			Err:  err,
+		}
+	}
+
+	*out = append(*out, byte(c.GearType))
+	return nil
+}
+
+// Encode encodes this block with its ID into the given output buffer
+func (c *CraftedGearTypeData) Encode(ver types.EncodingVersion, out *[]byte) error {
+	// Write block ID
+	*out = append(*out, byte(c.BlockID()))
+	// Write block data
+	return c.EncodeData(ver, out)
+}
+
+// DecodeData decodes data for this block from the given bytes
+func (c *CraftedGearTypeData) DecodeData(bytes []byte, ver types.EncodingVersion) (int, error) {
+	if len(bytes) < 1 {
+		return 0, &encoding.DecodeError{Type: encoding.ErrUnexpectedEndOfBytes}
+	}
+
+	gearType, err := types.CraftedGearTypeFromByte(bytes[0])
+	if err != nil {
+		return 0, &encoding.DecodeError{
+			Type: encoding.ErrBadGearType,
+			Err:  err,
+		}
+	}
+
+	c.GearType = gearType
+	return 1, nil
+}
+
+// NewCraftedGearTypeData creates a new CraftedGearTypeData block with the specified gear type
+func NewCraftedGearTypeData(gearType types.CraftedGearType) *CraftedGearTypeData {
+	return &CraftedGearTypeData{
+		GearType: gearType,
+	}
+}
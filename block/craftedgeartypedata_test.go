@@ -0,0 +1,45 @@
+package block
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+func TestCraftedGearTypeDataRoundTrip(t *testing.T) {
+	testCases := []struct {
+		gearType types.CraftedGearType
+		bytes    []byte
+	}{
+		{types.Spear, []byte{byte(BlockCraftedGearType), 0}},
+		{types.Relik, []byte{byte(BlockCraftedGearType), 4}},
+		{types.Boots, []byte{byte(BlockCraftedGearType), 11}},
+		{types.Weapon, []byte{byte(BlockCraftedGearType), 12}},
+		{types.Accessory, []byte{byte(BlockCraftedGearType), 13}},
+	}
+
+	for _, tc := range testCases {
+		encoded := roundTripBlock(t, NewCraftedGearTypeData(tc.gearType))
+		if !bytes.Equal(encoded, tc.bytes) {
+			t.Errorf("Incorrect encoding of %v. Expected %v, got %v", tc.gearType, tc.bytes, encoded)
+		}
+	}
+}
+
+func TestCraftedGearTypeDataBadGearType(t *testing.T) {
+	for _, id := range []byte{14, 42, 255} {
+		_, _, err := DecodeBlock(types.Version1, []byte{byte(BlockCraftedGearType), id})
+		if !errors.Is(err, encoding.ErrBadGearType) {
+			t.Errorf("Expected %v when decoding gear type %d, got %v", encoding.ErrBadGearType, id, err)
+		}
+
+		var out []byte
+		err = NewCraftedGearTypeData(types.CraftedGearType(id)).Encode(types.Version1, &out)
+		if !errors.Is(err, encoding.ErrBadGearType) {
+			t.Errorf("Expected %v when encoding gear type %d, got %v", encoding.ErrBadGearType, id, err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package block
+
+import (
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+// CraftedIdentificationData represents a block containing the identifications of a crafted item
+type CraftedIdentificationData struct {
+	// The identifications, holding their values at full durability
+	Identifications []*types.CraftedStat
+}
+
+// BlockID returns the ID of this block
+func (d *CraftedIdentificationData) BlockID() DataBlockID {
+	return BlockCraftedIdentificationData
+}
+
+// AsID returns the ID of this block
+func (d *CraftedIdentificationData) AsID() DataBlockID {
+	return d.BlockID()
+}
+
+// Encode encodes this block into the given output buffer
+func (d *CraftedIdentificationData) Encode(ver types.EncodingVersion, out *[]byte) error {
+	// Write the block ID
+	*out = append(*out, byte(d.BlockID()))
+
+	// Encode the data
+	return d.EncodeData(ver, out)
+}
+
+// EncodeData encodes this block's data into the given output buffer
+func (d *CraftedIdentificationData) EncodeData(ver types.EncodingVersion, out *[]byte) error {
+	// Check for too many identifications
+	if len(d.Identifications) > 255 {
+		return &encoding.EncodeError{
+			Type: encoding.ErrTooManyIdentifications,
+		}
+	}
+
+	// Add number of identifications
+	*out = append(*out, byte(len(d.Identifications)))
+
+	// Add the identifications
+	for i, ident := range d.Identifications {
+		if ident == nil {
+			return &encoding.EncodeError{
+				Type: encoding.ErrMissingIdentification,
+				Err:  missingIdentificationError(i),
+			}
+		}
+
+		*out = append(*out, ident.Kind)
+		*out = encoding.AppendVarInt(*out, int64(ident.Max))
+	}
+
+	return nil
+}
+
+// DecodeData decodes data for this block from the given bytes
+func (d *CraftedIdentificationData) DecodeData(bytes []byte, ver types.EncodingVersion) (int, error) {
+	return d.decodeDataWith(bytes, ver, nil)
+}
+
+// decodeDataWith decodes data for this block from the given bytes within the given limits
+func (d *CraftedIdentificationData) decodeDataWith(bytes []byte, ver types.EncodingVersion, opts *DecodeOptions) (int, error) {
+	if len(bytes) < 1 {
+		return 0, &encoding.DecodeError{
+			Type: encoding.ErrUnexpectedEndOfBytes,
+		}
+	}
+
+	// First byte is the number of identifications
+	identCount := int(bytes[0])
+	bytesUsed := 1
+
+	// Every stat takes at least two bytes, so don't trust counts the remaining bytes can't hold
+	if err := opts.checkIdentifications(identCount); err != nil {
+		return 0, err
+	}
+	if identCount*2 > len(bytes)-bytesUsed {
+		return len(bytes), &encoding.DecodeError{
+			Type: encoding.ErrUnexpectedEndOfBytes,
+		}
+	}
+
+	idents := make([]*types.CraftedStat, 0, identCount)
+	for i := 0; i < identCount; i++ {
+		// Get the stat ID
+		if len(bytes) <= bytesUsed {
+			return bytesUsed, &encoding.DecodeError{
+				Type: encoding.ErrUnexpectedEndOfBytes,
+			}
+		}
+		id := bytes[bytesUsed]
+		bytesUsed++
+
+		// Decode the max value
+		maxVal, n, err := opts.decodeVarInt32(bytes[bytesUsed:])
+		if err != nil {
+			return bytesUsed, err
+		}
+		bytesUsed += n
+
+		idents = append(idents, types.NewCraftedStat(id, maxVal))
+	}
+
+	d.Identifications = idents
+
+	return bytesUsed, nil
+}
+
+// missingIdentificationError describes a nil identification at the given index
+func missingIdentificationError(index int) error {
+	return &encoding.ValueError{
+		Name:   "identification at index",
+		Value:  int64(index),
+		Reason: "is nil",
+	}
+}
+
+// NewCraftedIdentificationData creates a new CraftedIdentificationData block with the given stats
+func NewCraftedIdentificationData(identifications []*types.CraftedStat) *CraftedIdentificationData {
+	return &CraftedIdentificationData{
+		Identifications: identifications,
+	}
+}
@@ -0,0 +1,71 @@
+package block
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+func TestCraftedIdentificationDataRoundTrip(t *testing.T) {
+	id := byte(BlockCraftedIdentificationData)
+
+	testCases := []struct {
+		block *CraftedIdentificationData
+		bytes []byte
+	}{
+		{NewCraftedIdentificationData([]*types.CraftedStat{}), []byte{id, 0}},
+		{NewCraftedIdentificationData([]*types.CraftedStat{types.NewCraftedStat(3, 42)}), []byte{id, 1, 3, 84}},
+		{NewCraftedIdentificationData([]*types.CraftedStat{
+			types.NewCraftedStat(56, -1),
+			types.NewCraftedStat(9, 100),
+			types.NewCraftedStat(3, 0),
+		}), []byte{id, 3, 56, 1, 9, 0xC8, 0x01, 3, 0}},
+	}
+
+	for _, tc := range testCases {
+		encoded := roundTripBlock(t, tc.block)
+		if !bytes.Equal(encoded, tc.bytes) {
+			t.Errorf("Incorrect encoding of %+v. Expected %v, got %v", tc.block, tc.bytes, encoded)
+		}
+	}
+}
+
+func TestCraftedIdentificationDataInvalid(t *testing.T) {
+	testCases := []struct {
+		name  string
+		stats []*types.CraftedStat
+		kind  encoding.ErrorKind
+	}{
+		{"nil stat", []*types.CraftedStat{types.NewCraftedStat(3, 42), nil}, encoding.ErrMissingIdentification},
+		{"too many stats", make([]*types.CraftedStat, 256), encoding.ErrTooManyIdentifications},
+	}
+
+	for _, tc := range testCases {
+		var out []byte
+		if err := NewCraftedIdentificationData(tc.stats).Encode(types.Version1, &out); !errors.Is(err, tc.kind) {
+			t.Errorf("%s: Expected %v, got %v", tc.name, tc.kind, err)
+		}
+	}
+}
+
+func TestCraftedIdentificationDataLimits(t *testing.T) {
+	id := byte(BlockCraftedIdentificationData)
+	testCases := []struct {
+		name  string
+		bytes []byte
+		opts  *DecodeOptions
+		kind  encoding.ErrorKind
+	}{
+		{"count beyond data", []byte{id, 3, 1, 2}, nil, encoding.ErrUnexpectedEndOfBytes},
+		{"count beyond limit", []byte{id, 2, 1, 2, 3, 4}, &DecodeOptions{MaxIdentifications: 1}, encoding.ErrLimitExceeded},
+	}
+
+	for _, tc := range testCases {
+		if _, _, err := decodeBlock(types.Version1, tc.bytes, tc.opts); !errors.Is(err, tc.kind) {
+			t.Errorf("%s: Expected %v, got %v", tc.name, tc.kind, err)
+		}
+	}
+}
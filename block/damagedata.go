@@ -0,0 +1,232 @@
+package block
+
+import (
+	"fmt"
+
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+// neutralDamageID is the damage type ID used for neutral damage, following the element IDs
+const neutralDamageID byte = 5
+
+// DamageData represents the damage block of a crafted weapon in an encoded item string.
+// Damages are encoded with the neutral damage first, followed by the elemental damages in element order.
+// Damages in any other order are rejected when decoding, as they would not encode back to the same bytes.
+type DamageData struct {
+	// AttackSpeed is the attack speed of the weapon
+	AttackSpeed types.AttackSpeed
+	// Neutral is the neutral damage range, nil if the weapon deals no neutral damage
+	Neutral *types.ElementalRange
+	// Elemental contains the damage range for every element the weapon deals damage with
+	Elemental map[types.Element]types.ElementalRange
+}
+
+// BlockID returns the ID of this block
+func (d *DamageData) BlockID() DataBlockID {
+	return BlockDamageData
+}
+
+// AsID returns the ID of this block
+func (d *DamageData) AsID() DataBlockID {
+	return d.BlockID()
+}
+
+// EncodeData encodes this block's data into the given output buffer
+func (d *DamageData) EncodeData(ver types.EncodingVersion, out *[]byte) error {
+	if _, err := types.AttackSpeedFromByte(byte(d.AttackSpeed)); err != nil {
+		return &encoding.EncodeError{
+			Type: encoding.ErrBadAttackSpeed,
+			Err:  err,
+		}
+	}
+
+	// Count the damages and make sure they are valid
+	count := len(d.Elemental)
+	if d.Neutral != nil {
+		if !d.Neutral.Valid() {
+			return &encoding.EncodeError{
+				Type: encoding.ErrInvalidDamage,
+				Err:  invalidRangeError("Neutral", *d.Neutral),
+			}
+		}
+		count++
+	}
+	for elem, damage := range d.Elemental {
+		if _, err := types.ElementFromByte(byte(elem)); err != nil {
+			return &encoding.EncodeError{
+				Type: encoding.ErrBadElement,
+				Err:  err,
+			}
+		}
+		if !damage.Valid() {
+			return &encoding.EncodeError{
+				Type: encoding.ErrInvalidDamage,
+				Err:  invalidRangeError(elem.String(), damage),
+			}
+		}
+	}
+
+	// Write the attack speed and the number of damages
+	*out = append(*out, byte(d.AttackSpeed))
+	*out = append(*out, byte(count))
+
+	// Write the neutral damage
+	if d.Neutral != nil {
+		appendDamage(out, neutralDamageID, *d.Neutral)
+	}
+
+	// Write the elemental damages in element order
+	for elem := types.Earth; elem <= types.Air; elem++ {
+		if damage, ok := d.Elemental[elem]; ok {
+			appendDamage(out, byte(elem), damage)
+		}
+	}
+
+	return nil
+}
+
+// appendDamage writes a single damage type and range into the given output buffer
+func appendDamage(out *[]byte, id byte, damage types.ElementalRange) {
+	*out = append(*out, id)
+	*out = encoding.AppendVarInt(*out, int64(damage.Min))
+	*out = encoding.AppendVarInt(*out, int64(damage.Max))
+}
+
+// Encode encodes this block with its ID into the given output buffer
+func (d *DamageData) Encode(ver types.EncodingVersion, out *[]byte) error {
+	// Write block ID
+	*out = append(*out, byte(d.BlockID()))
+	// Write block data
+	return d.EncodeData(ver, out)
+}
+
+// DecodeData decodes data for this block from the given bytes
+func (d *DamageData) DecodeData(bytes []byte, ver types.EncodingVersion) (int, error) {
+	return d.decodeDataWith(bytes, ver, nil)
+}
+
+// decodeDataWith decodes data for this block from the given bytes within the given limits
+func (d *DamageData) decodeDataWith(bytes []byte, ver types.EncodingVersion, opts *DecodeOptions) (int, error) {
+	if len(bytes) < 2 {
+		return 0, &encoding.DecodeError{Type: encoding.ErrUnexpectedEndOfBytes}
+	}
+
+	// Read the attack speed
+	attackSpeed, err := types.AttackSpeedFromByte(bytes[0])
+	if err != nil {
+		return 0, &encoding.DecodeError{
+			Type: encoding.ErrBadAttackSpeed,
+			Err:  err,
+		}
+	}
+
+	// Read the number of damages
+	count := int(bytes[1])
+	bytesUsed := 2
+
+	var neutral *types.ElementalRange
+	elemental := make(map[types.Element]types.ElementalRange)
+	prev := -1
+
+	for i := 0; i < count; i++ {
+		entryStart := bytesUsed
+		if len(bytes) <= bytesUsed {
+			return bytesUsed, &encoding.DecodeError{Type: encoding.ErrUnexpectedEndOfBytes}
+		}
+		id := bytes[bytesUsed]
+		bytesUsed++
+
+		// Read the damage range
+		minVal, n, err := opts.decodeVarInt32(bytes[bytesUsed:])
+		if err != nil {
+			return bytesUsed, err
+		}
+		bytesUsed += n
+
+		maxVal, n, err := opts.decodeVarInt32(bytes[bytesUsed:])
+		if err != nil {
+			return bytesUsed, err
+		}
+		bytesUsed += n
+
+		damage := types.NewElementalRange(minVal, maxVal)
+
+		if id == neutralDamageID {
+			if !damage.Valid() {
+				return entryStart, &encoding.DecodeError{
+					Type: encoding.ErrInvalidDamage,
+					Err:  invalidRangeError("Neutral", damage),
+				}
+			}
+			if neutral != nil {
+				return entryStart, &encoding.DecodeError{
+					Type: encoding.ErrInvalidDamage,
+					Err:  duplicateError("Neutral damage type", int64(id)),
+				}
+			}
+			if prev >= 0 {
+				return entryStart, &encoding.DecodeError{
+					Type: encoding.ErrNonCanonical,
+					Err:  outOfOrderError("Neutral damage type", int64(id)),
+				}
+			}
+			neutral = &damage
+			continue
+		}
+
+		elem, err := types.ElementFromByte(id)
+		if err != nil {
+			return entryStart, &encoding.DecodeError{
+				Type: encoding.ErrBadElement,
+				Err:  err,
+			}
+		}
+		if !damage.Valid() {
+			return entryStart, &encoding.DecodeError{
+				Type: encoding.ErrInvalidDamage,
+				Err:  invalidRangeError(elem.String(), damage),
+			}
+		}
+		if _, ok := elemental[elem]; ok {
+			return entryStart, &encoding.DecodeError{
+				Type: encoding.ErrInvalidDamage,
+				Err:  duplicateError(elem.String()+" damage type", int64(id)),
+			}
+		}
+
+		// The map doesn't keep the order, so only the order it is encoded in is accepted
+		if int(elem) < prev {
+			return entryStart, &encoding.DecodeError{
+				Type: encoding.ErrNonCanonical,
+				Err:  outOfOrderError(elem.String()+" damage type", int64(id)),
+			}
+		}
+		prev = int(elem)
+		elemental[elem] = damage
+	}
+
+	d.AttackSpeed = attackSpeed
+	d.Neutral = neutral
+	d.Elemental = elemental
+
+	return bytesUsed, nil
+}
+
+// invalidRangeError describes a damage range whose minimum exceeds its maximum
+func invalidRangeError(name string, damage types.ElementalRange) error {
+	return &encoding.ValueError{
+		Name:   name + " minimum damage",
+		Value:  int64(damage.Min),
+		Reason: fmt.Sprintf("exceeds maximum damage %d", damage.Max),
+	}
+}
+
+// NewDamageData creates a new DamageData block with the specified attack speed and damages
+func NewDamageData(attackSpeed types.AttackSpeed, neutral *types.ElementalRange, elemental map[types.Element]types.ElementalRange) *DamageData {
+	return &DamageData{
+		AttackSpeed: attackSpeed,
+		Neutral:     neutral,
+		Elemental:   elemental,
+	}
+}
@@ -0,0 +1,113 @@
+package block
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+func TestDamageDataRoundTrip(t *testing.T) {
+	id := byte(BlockDamageData)
+	neutral := types.NewElementalRange(10, 20)
+	fixed := types.NewElementalRange(-3, -3)
+
+	testCases := []struct {
+		block *DamageData
+		bytes []byte
+	}{
+		{NewDamageData(types.SuperFast, nil, map[types.Element]types.ElementalRange{}), []byte{id, 0, 0}},
+		{NewDamageData(types.Fast, &neutral, map[types.Element]types.ElementalRange{
+			types.Fire: types.NewElementalRange(5, 9),
+		}), []byte{id, 2, 2, 5, 20, 40, 3, 10, 18}},
+		{NewDamageData(types.SuperSlow, &fixed, map[types.Element]types.ElementalRange{}), []byte{id, 6, 1, 5, 5, 5}},
+		// Elemental damages are written in element order after the neutral damage
+		{NewDamageData(types.Normal, &neutral, map[types.Element]types.ElementalRange{
+			types.Air:     types.NewElementalRange(0, 1),
+			types.Earth:   types.NewElementalRange(2, 2),
+			types.Thunder: types.NewElementalRange(0, 64),
+		}), []byte{id, 3, 4, 5, 20, 40, 0, 4, 4, 1, 0, 0x80, 0x01, 4, 0, 2}},
+	}
+
+	for _, tc := range testCases {
+		encoded := roundTripBlock(t, tc.block)
+		if !bytes.Equal(encoded, tc.bytes) {
+			t.Errorf("Incorrect encoding of %+v. Expected %v, got %v", tc.block, tc.bytes, encoded)
+		}
+	}
+}
+
+func TestDamageDataInvalid(t *testing.T) {
+	id := byte(BlockDamageData)
+	inverted := types.NewElementalRange(9, 5)
+
+	testCases := []struct {
+		name   string
+		block  *DamageData
+		bytes  []byte
+		kind   encoding.ErrorKind
+		offset int
+	}{
+		{"bad attack speed", NewDamageData(7, nil, nil), []byte{id, 7, 0}, encoding.ErrBadAttackSpeed, 1},
+		{"bad element", NewDamageData(types.Fast, nil, map[types.Element]types.ElementalRange{
+			types.Blood: types.NewElementalRange(1, 2),
+		}), []byte{id, 2, 1, 6, 2, 4}, encoding.ErrBadElement, 3},
+		{"neutral min above max", NewDamageData(types.Fast, &inverted, nil), []byte{id, 2, 1, 5, 18, 10}, encoding.ErrInvalidDamage, 3},
+		{"elemental min above max", NewDamageData(types.Fast, nil, map[types.Element]types.ElementalRange{
+			types.Water: inverted,
+		}), []byte{id, 2, 1, 2, 18, 10}, encoding.ErrInvalidDamage, 3},
+	}
+
+	for _, tc := range testCases {
+		var out []byte
+		if err := tc.block.Encode(types.Version1, &out); !errors.Is(err, tc.kind) {
+			t.Errorf("%s: Expected %v when encoding, got %v", tc.name, tc.kind, err)
+		}
+
+		_, _, err := DecodeBlock(types.Version1, tc.bytes)
+		var decoderErr *encoding.DecoderError
+		if !errors.Is(err, tc.kind) || !errors.As(err, &decoderErr) || decoderErr.Offset != tc.offset {
+			t.Errorf("%s: Expected %v at offset %d when decoding, got %v", tc.name, tc.kind, tc.offset, err)
+		}
+	}
+}
+
+func TestDamageDataDuplicate(t *testing.T) {
+	id := byte(BlockDamageData)
+	testCases := []struct {
+		name  string
+		bytes []byte
+	}{
+		{"neutral", []byte{id, 2, 2, 5, 2, 4, 5, 2, 4}},
+		{"elemental", []byte{id, 2, 2, 1, 2, 4, 1, 2, 4}},
+	}
+
+	for _, tc := range testCases {
+		_, _, err := DecodeBlock(types.Version1, tc.bytes)
+		var decoderErr *encoding.DecoderError
+		if !errors.Is(err, encoding.ErrInvalidDamage) || !errors.As(err, &decoderErr) || decoderErr.Offset != 6 {
+			t.Errorf("%s: Expected %v at offset 6, got %v", tc.name, encoding.ErrInvalidDamage, err)
+		}
+	}
+}
+
+func TestElementalRangeValid(t *testing.T) {
+	testCases := []struct {
+		damage   types.ElementalRange
+		expected bool
+	}{
+		{types.NewElementalRange(0, 0), true},
+		{types.NewElementalRange(1, 2), true},
+		{types.NewElementalRange(-5, -1), true},
+		{types.NewElementalRange(2, 1), false},
+		{types.NewElementalRange(-1, -5), false},
+	}
+
+	for _, tc := range testCases {
+		if actual := tc.damage.Valid(); actual != tc.expected {
+			t.Errorf("Incorrect validity of %v. Expected %v, got %v", tc.damage, tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package block
+
+import (
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+// DefenseData represents the defense block of crafted armour or accessories in an encoded item string.
+// Elemental defenses are encoded in element order and may be negative.
+// Defenses in any other order are rejected when decoding, as they would not encode back to the same bytes.
+type DefenseData struct {
+	// Health is the health bonus of the item
+	Health int32
+	// Defenses contains the defense value for every element the item has a defense in
+	Defenses map[types.Element]int32
+}
+
+// BlockID returns the ID of this block
+func (d *DefenseData) BlockID() DataBlockID {
+	return BlockDefenseData
+}
+
+// AsID returns the ID of this block
+func (d *DefenseData) AsID() DataBlockID {
+	return d.BlockID()
+}
+
+// EncodeData encodes this block's data into the given output buffer
+func (d *DefenseData) EncodeData(ver types.EncodingVersion, out *[]byte) error {
+	for elem := range d.Defenses {
+		if _, err := types.ElementFromByte(byte(elem)); err != nil {
+			return &encoding.EncodeError{
+				Type: encoding.ErrBadElement,
+				Err:  err,
+			}
+		}
+	}
+
+	// Write the health
+	*out = encoding.AppendVarInt(*out, int64(d.Health))
+
+	// Write the number of defenses
+	*out = append(*out, byte(len(d.Defenses)))
+
+	// Write the elemental defenses in element order
+	for elem := types.Earth; elem <= types.Air; elem++ {
+		if defense, ok := d.Defenses[elem]; ok {
+			*out = append(*out, byte(elem))
+			*out = encoding.AppendVarInt(*out, int64(defense))
+		}
+	}
+
+	return nil
+}
+
+// Encode encodes this block with its ID into the given output buffer
+func (d *DefenseData) Encode(ver types.EncodingVersion, out *[]byte) error {
+	// Write block ID
+	*out = append(*out, byte(d.BlockID()))
+	// Write block data
+	return d.EncodeData(ver, out)
+}
+
+// DecodeData decodes data for this block from the given bytes
+func (d *DefenseData) DecodeData(bytes []byte, ver types.EncodingVersion) (int, error) {
+	return d.decodeDataWith(bytes, ver, nil)
+}
+
+// decodeDataWith decodes data for this block from the given bytes within the given limits
+func (d *DefenseData) decodeDataWith(bytes []byte, ver types.EncodingVersion, opts *DecodeOptions) (int, error) {
+	// Read the health
+	health, bytesUsed, err := opts.decodeVarInt32(bytes)
+	if err != nil {
+		return 0, err
+	}
+
+	// Read the number of defenses
+	if len(bytes) <= bytesUsed {
+		return bytesUsed, &encoding.DecodeError{Type: encoding.ErrUnexpectedEndOfBytes}
+	}
+	count := int(bytes[bytesUsed])
+	bytesUsed++
+
+	defenses := make(map[types.Element]int32)
+	prev := -1
+	for i := 0; i < count; i++ {
+		if len(bytes) <= bytesUsed {
+			return bytesUsed, &encoding.DecodeError{Type: encoding.ErrUnexpectedEndOfBytes}
+		}
+
+		elem, err := types.ElementFromByte(bytes[bytesUsed])
+		if err != nil {
+			return bytesUsed, &encoding.DecodeError{
+				Type: encoding.ErrBadElement,
+				Err:  err,
+			}
+		}
+		if _, ok := defenses[elem]; ok {
+			return bytesUsed, &encoding.DecodeError{
+				Type: encoding.ErrBadElement,
+				Err:  duplicateError(elem.String()+" defense element", int64(elem)),
+			}
+		}
+
+		// The map doesn't keep the order, so only the order it is encoded in is accepted
+		if int(elem) < prev {
+			return bytesUsed, &encoding.DecodeError{
+				Type: encoding.ErrNonCanonical,
+				Err:  outOfOrderError(elem.String()+" defense element", int64(elem)),
+			}
+		}
+		prev = int(elem)
+		bytesUsed++
+
+		defense, n, err := opts.decodeVarInt32(bytes[bytesUsed:])
+		if err != nil {
+			return bytesUsed, err
+		}
+		bytesUsed += n
+
+		defenses[elem] = defense
+	}
+
+	d.Health = health
+	d.Defenses = defenses
+
+	return bytesUsed, nil
+}
+
+// NewDefenseData creates a new DefenseData block with the specified health and elemental defenses
+func NewDefenseData(health int32, defenses map[types.Element]int32) *DefenseData {
+	return &DefenseData{
+		Health:   health,
+		Defenses: defenses,
+	}
+}
@@ -0,0 +1,58 @@
+package block
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+func TestDefenseDataRoundTrip(t *testing.T) {
+	testCases := []*DefenseData{
+		NewDefenseData(0, map[types.Element]int32{}),
+		NewDefenseData(1250, map[types.Element]int32{types.Earth: 80, types.Air: 45}),
+		NewDefenseData(-300, map[types.Element]int32{types.Fire: -120}),
+		NewDefenseData(3000, map[types.Element]int32{
+			types.Earth:   -1,
+			types.Thunder: 2147483647,
+			types.Water:   -2147483648,
+			types.Fire:    64,
+			types.Air:     -64,
+		}),
+	}
+
+	for _, tc := range testCases {
+		roundTripBlock(t, tc)
+	}
+}
+
+func TestDefenseDataBadElement(t *testing.T) {
+	block := NewDefenseData(100, map[types.Element]int32{types.Blood: 10})
+
+	var bytes []byte
+	if err := block.Encode(types.Version1, &bytes); !errors.Is(err, encoding.ErrBadElement) {
+		t.Errorf("Expected %v when encoding a defense for element %v, got %v", encoding.ErrBadElement, types.Blood, err)
+	}
+}
+
+func TestDefenseDataInvalid(t *testing.T) {
+	id := byte(BlockDefenseData)
+	testCases := []struct {
+		name   string
+		bytes  []byte
+		offset int
+	}{
+		{"duplicate element", []byte{id, 0, 2, 1, 2, 1, 4}, 5},
+		{"unknown element", []byte{id, 0, 1, 9, 2}, 3},
+		{"blood element", []byte{id, 0, 1, byte(types.Blood), 2}, 3},
+	}
+
+	for _, tc := range testCases {
+		_, _, err := DecodeBlock(types.Version1, tc.bytes)
+		var decoderErr *encoding.DecoderError
+		if !errors.Is(err, encoding.ErrBadElement) || !errors.As(err, &decoderErr) || decoderErr.Offset != tc.offset {
+			t.Errorf("%s: Expected %v at offset %d, got %v", tc.name, encoding.ErrBadElement, tc.offset, err)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package block
+
+import (
+	"fmt"
+
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+// DurabilityData represents the durability block of a crafted item in an encoded item string.
+// The effect strength describes how effective the identifications of the item currently are.
+type DurabilityData struct {
+	// EffectStrength is the effectiveness of the item's identifications in percent
+	EffectStrength byte
+	// Max is the maximum durability of the item
+	Max int32
+	// Current is the current durability of the item
+	Current int32
+}
+
+// BlockID returns the ID of this block
+func (d *DurabilityData) BlockID() DataBlockID {
+	return BlockDurabilityData
+}
+
+// AsID returns the ID of this block
+func (d *DurabilityData) AsID() DataBlockID {
+	return d.BlockID()
+}
+
+// Effectiveness returns the effectiveness of the item's identifications as a fraction,
+// where 1.0 means the identifications apply at their full value
+func (d *DurabilityData) Effectiveness() float64 {
+	return float64(d.EffectStrength) / 100
+}
+
+// EncodeData encodes this block's data into the given output buffer
+func (d *DurabilityData) EncodeData(ver types.EncodingVersion, out *[]byte) error {
+	if d.Current > d.Max {
+		return &encoding.EncodeError{
+			Type: encoding.ErrInvalidDurability,
+			Err:  durabilityError(int64(d.Current), int64(d.Max)),
+		}
+	}
+
+	// Write the effect strength
+	*out = append(*out, d.EffectStrength)
+	// Write the maximum and current durability
+	*out = encoding.AppendVarInt(*out, int64(d.Max))
+	*out = encoding.AppendVarInt(*out, int64(d.Current))
+
+	return nil
+}
+
+// Encode encodes this block with its ID into the given output buffer
+func (d *DurabilityData) Encode(ver types.EncodingVersion, out *[]byte) error {
+	// Write block ID
+	*out = append(*out, byte(d.BlockID()))
+	// Write block data
+	return d.EncodeData(ver, out)
+}
+
+// DecodeData decodes data for this block from the given bytes
+func (d *DurabilityData) DecodeData(bytes []byte, ver types.EncodingVersion) (int, error) {
+	return d.decodeDataWith(bytes, ver, nil)
+}
+
+// decodeDataWith decodes data for this block from the given bytes within the given limits
+func (d *DurabilityData) decodeDataWith(bytes []byte, ver types.EncodingVersion, opts *DecodeOptions) (int, error) {
+	if len(bytes) < 1 {
+		return 0, &encoding.DecodeError{Type: encoding.ErrUnexpectedEndOfBytes}
+	}
+
+	// Read the effect strength
+	effectStrength := bytes[0]
+	bytesUsed := 1
+
+	// Read the maximum durability
+	maxVal, n, err := opts.decodeVarInt32(bytes[bytesUsed:])
+	if err != nil {
+		return bytesUsed, err
+	}
+	bytesUsed += n
+
+	// Read the current durability
+	curStart := bytesUsed
+	curVal, n, err := opts.decodeVarInt32(bytes[bytesUsed:])
+	if err != nil {
+		return bytesUsed, err
+	}
+	bytesUsed += n
+
+	if curVal > maxVal {
+		return curStart, &encoding.DecodeError{
+			Type: encoding.ErrInvalidDurability,
+			Err:  durabilityError(int64(curVal), int64(maxVal)),
+		}
+	}
+
+	d.EffectStrength = effectStrength
+	d.Max = maxVal
+	d.Current = curVal
+
+	return bytesUsed, nil
+}
+
+// durabilityError describes a current durability exceeding the maximum durability
+func durabilityError(current int64, max int64) error {
+	return &encoding.ValueError{
+		Name:   "current durability",
+		Value:  current,
+		Reason: fmt.Sprintf("exceeds maximum durability %d", max),
+	}
+}
+
+// NewDurabilityData creates a new DurabilityData block with the specified values
+func NewDurabilityData(effectStrength byte, max int32, current int32) *DurabilityData {
+	return &DurabilityData{
+		EffectStrength: effectStrength,
+		Max:            max,
+		Current:        current,
+	}
+}
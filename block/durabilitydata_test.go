@@ -0,0 +1,62 @@
+package block
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+func TestDurabilityDataRoundTrip(t *testing.T) {
+	testCases := []struct {
+		block *DurabilityData
+		bytes []byte
+	}{
+		{NewDurabilityData(0, 0, 0), []byte{byte(BlockDurabilityData), 0, 0, 0}},
+		{NewDurabilityData(100, 1, 1), []byte{byte(BlockDurabilityData), 100, 2, 2}},
+		{NewDurabilityData(95, 120, 100), []byte{byte(BlockDurabilityData), 95, 0xF0, 0x01, 0xC8, 0x01}},
+		{NewDurabilityData(10, 2147483647, -5), append([]byte{byte(BlockDurabilityData), 10}, append(encoding.EncodeVarInt(2147483647), 9)...)},
+	}
+
+	for _, tc := range testCases {
+		encoded := roundTripBlock(t, tc.block)
+		if !bytes.Equal(encoded, tc.bytes) {
+			t.Errorf("Incorrect encoding of %+v. Expected %v, got %v", tc.block, tc.bytes, encoded)
+		}
+	}
+}
+
+func TestDurabilityDataCurrentExceedsMax(t *testing.T) {
+	var out []byte
+	err := NewDurabilityData(100, 50, 51).Encode(types.Version1, &out)
+	if !errors.Is(err, encoding.ErrInvalidDurability) {
+		t.Errorf("Expected %v when encoding, got %v", encoding.ErrInvalidDurability, err)
+	}
+
+	// The error points at the current durability
+	_, _, err = DecodeBlock(types.Version1, []byte{byte(BlockDurabilityData), 100, 2, 4})
+	var decoderErr *encoding.DecoderError
+	if !errors.Is(err, encoding.ErrInvalidDurability) || !errors.As(err, &decoderErr) || decoderErr.Offset != 3 {
+		t.Errorf("Expected %v at offset 3 when decoding, got %v", encoding.ErrInvalidDurability, err)
+	}
+}
+
+func TestDurabilityDataEffectiveness(t *testing.T) {
+	testCases := []struct {
+		effectStrength byte
+		expected       float64
+	}{
+		{0, 0},
+		{50, 0.5},
+		{100, 1},
+		{150, 1.5},
+	}
+
+	for _, tc := range testCases {
+		if actual := NewDurabilityData(tc.effectStrength, 10, 10).Effectiveness(); actual != tc.expected {
+			t.Errorf("Incorrect effectiveness for strength %d. Expected %v, got %v", tc.effectStrength, tc.expected, actual)
+		}
+	}
+}
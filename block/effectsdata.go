@@ -0,0 +1,109 @@
+package block
+
+import (
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+// EffectsData represents the effects block of a crafted consumable in an encoded item string.
+type EffectsData struct {
+	// Effects is a list of effects applied when the consumable is used
+	Effects []types.Effect
+}
+
+// BlockID returns the ID of this block
+func (e *EffectsData) BlockID() DataBlockID {
+	return BlockEffectsData
+}
+
+// AsID returns the ID of this block
+func (e *EffectsData) AsID() DataBlockID {
+	return e.BlockID()
+}
+
+// EncodeData encodes this block's data into the given output buffer
+func (e *EffectsData) EncodeData(ver types.EncodingVersion, out *[]byte) error {
+	// Check for too many effects
+	if len(e.Effects) > 255 {
+		return &encoding.EncodeError{Type: encoding.ErrTooManyEffects}
+	}
+
+	for _, effect := range e.Effects {
+		if _, err := types.EffectTypeFromByte(byte(effect.Kind)); err != nil {
+			return &encoding.EncodeError{
+				Type: encoding.ErrBadEffectType,
+				Err:  err,
+			}
+		}
+	}
+
+	// Write the number of effects
+	*out = append(*out, byte(len(e.Effects)))
+
+	// Write the effects
+	for _, effect := range e.Effects {
+		*out = append(*out, byte(effect.Kind))
+		*out = encoding.AppendVarInt(*out, int64(effect.Value))
+	}
+
+	return nil
+}
+
+// Encode encodes this block with its ID into the given output buffer
+func (e *EffectsData) Encode(ver types.EncodingVersion, out *[]byte) error {
+	// Write block ID
+	*out = append(*out, byte(e.BlockID()))
+	// Write block data
+	return e.EncodeData(ver, out)
+}
+
+// DecodeData decodes data for this block from the given bytes
+func (e *EffectsData) DecodeData(bytes []byte, ver types.EncodingVersion) (int, error) {
+	return e.decodeDataWith(bytes, ver, nil)
+}
+
+// decodeDataWith decodes data for this block from the given bytes within the given limits
+func (e *EffectsData) decodeDataWith(bytes []byte, ver types.EncodingVersion, opts *DecodeOptions) (int, error) {
+	if len(bytes) < 1 {
+		return 0, &encoding.DecodeError{Type: encoding.ErrUnexpectedEndOfBytes}
+	}
+
+	// Read the number of effects
+	effectCount := int(bytes[0])
+	bytesUsed := 1
+
+	effects := make([]types.Effect, 0)
+	for i := 0; i < effectCount; i++ {
+		if len(bytes) <= bytesUsed {
+			return bytesUsed, &encoding.DecodeError{Type: encoding.ErrUnexpectedEndOfBytes}
+		}
+
+		kind, err := types.EffectTypeFromByte(bytes[bytesUsed])
+		if err != nil {
+			return bytesUsed, &encoding.DecodeError{
+				Type: encoding.ErrBadEffectType,
+				Err:  err,
+			}
+		}
+		bytesUsed++
+
+		value, n, err := opts.decodeVarInt32(bytes[bytesUsed:])
+		if err != nil {
+			return bytesUsed, err
+		}
+		bytesUsed += n
+
+		effects = append(effects, types.Effect{Kind: kind, Value: value})
+	}
+
+	e.Effects = effects
+
+	return bytesUsed, nil
+}
+
+// NewEffectsData creates a new EffectsData block with the specified effects
+func NewEffectsData(effects []types.Effect) *EffectsData {
+	return &EffectsData{
+		Effects: effects,
+	}
+}
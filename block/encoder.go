@@ -1,19 +1,27 @@
 package block
 
 import (
+	"errors"
+
 	"github.com/AevtJJ/idmangler/encoding"
 	"github.com/AevtJJ/idmangler/types"
 )
 
 // ItemEncoder handles the encoding of blocks to ID strings
 type ItemEncoder struct {
-	version types.EncodingVersion
+	version        types.EncodingVersion
+	validateSchema bool
+	sortBlocks     bool
+	validateOrder  bool
 }
 
-// NewItemEncoder creates a new ItemEncoder with the default version
+// NewItemEncoder creates a new ItemEncoder with the default version and schema validation enabled.
+// Blocks which break the schema of their item type fail with ErrSchemaViolation,
+// use WithSchemaValidation(false) to encode them anyway.
 func NewItemEncoder() *ItemEncoder {
 	return &ItemEncoder{
-		version: types.Version1,
+		version:        types.Version1,
+		validateSchema: true,
 	}
 }
 
@@ -23,63 +31,171 @@ func (e *ItemEncoder) WithVersion(version types.EncodingVersion) *ItemEncoder {
 	return e
 }
 
+// WithSchemaValidation enables or disables checking the blocks against the schema of their item type
+// and returns the encoder
+func (e *ItemEncoder) WithSchemaValidation(enabled bool) *ItemEncoder {
+	e.validateSchema = enabled
+	return e
+}
+
+// WithSorting enables or disables sorting the blocks into canonical order before encoding
+// and returns the encoder. Sorting guarantees that equal items always encode to identical strings.
+func (e *ItemEncoder) WithSorting(enabled bool) *ItemEncoder {
+	e.sortBlocks = enabled
+	return e
+}
+
+// WithOrderValidation enables or disables rejecting blocks which are out of canonical order
+// or duplicated and returns the encoder
+func (e *ItemEncoder) WithOrderValidation(enabled bool) *ItemEncoder {
+	e.validateOrder = enabled
+	return e
+}
+
 // EncodeBlocks encodes a series of blocks to an ID string
 func (e *ItemEncoder) EncodeBlocks(blocks []AnyBlock) (string, error) {
-	// Ensure we have at least a start block
+	var bytes []byte
+	if err := e.encodeBlocks(blocks, &bytes); err != nil {
+		return "", err
+	}
+
+	// Convert bytes to string
+	return encoding.EncodeString(bytes), nil
+}
+
+// encodeBlocks encodes a series of blocks into the given output buffer
+func (e *ItemEncoder) encodeBlocks(blocks []AnyBlock, out *[]byte) error {
+	// Sort the blocks into canonical order
+	if e.sortBlocks {
+		blocks = SortBlocks(blocks)
+	}
+
+	// Ensure we have at least a start block, an existing one sets the version of this item
+	ver := e.version
 	if len(blocks) == 0 || blocks[0].AsID() != BlockStartData {
 		startBlock := NewStartData(e.version)
 		blocks = append([]AnyBlock{startBlock}, blocks...)
-	} else {
-		// Update the version in the start block
-		if startBlock, ok := blocks[0].(*StartData); ok {
-			e.version = startBlock.Version
-		}
+	} else if startBlock, ok := blocks[0].(*StartData); ok {
+		ver = startBlock.Version
 	}
+	// Every block is encoded with the version the start block writes
+	ver = effectiveVersion(ver)
 
-	// Ensure we have an end block at the end
-	if len(blocks) == 0 || blocks[len(blocks)-1].AsID() != BlockEndData {
+	// Ensure we have an end block at the end, a raw tail already contains the original end
+	if _, ok := blocks[len(blocks)-1].(*RawTailBlock); !ok && blocks[len(blocks)-1].AsID() != BlockEndData {
 		endBlock := NewEndData()
 		blocks = append(blocks, endBlock)
 	}
 
+	// Check that the blocks are in canonical order
+	if e.validateOrder {
+		if err := ValidateOrder(blocks); err != nil {
+			return &encoding.EncodeError{
+				Type: encoding.ErrBlockOrder,
+				Err:  err,
+			}
+		}
+	}
+
+	// Check the blocks against the schema of the item type
+	if e.validateSchema {
+		if err := ValidateSchema(blocks); err != nil {
+			return &encoding.EncodeError{
+				Type: encoding.ErrSchemaViolation,
+				Err:  err,
+			}
+		}
+	}
+
 	// Encode all blocks
-	var bytes []byte
 	for _, b := range blocks {
-		if err := b.Encode(e.version, &bytes); err != nil {
-			return "", err
+		if err := b.Encode(ver, out); err != nil {
+			return err
 		}
 	}
 
-	// Convert bytes to string
-	return encoding.EncodeString(bytes), nil
+	return nil
 }
 
 // ItemDecoder handles the decoding of ID strings to blocks
-type ItemDecoder struct{}
+type ItemDecoder struct {
+	validateSchema  bool
+	preserveUnknown bool
+	strict          bool
+	options         DecodeOptions
+}
 
-// NewItemDecoder creates a new ItemDecoder
+// NewItemDecoder creates a new ItemDecoder with schema validation enabled and the default decode limits.
+// Items which break the schema of their item type fail with ErrSchemaViolation,
+// use WithSchemaValidation(false) to decode them anyway.
 func NewItemDecoder() *ItemDecoder {
-	return &ItemDecoder{}
+	return &ItemDecoder{
+		validateSchema: true,
+		options:        DefaultDecodeOptions(),
+	}
+}
+
+// WithStrict enables or disables strict decoding and returns the decoder.
+// In strict mode bytes after the EndData block, a missing EndData block and a repeated
+// TypeData, NameData, IdentificationData, PowderData or RerollData block are reported as errors.
+func (d *ItemDecoder) WithStrict(strict bool) *ItemDecoder {
+	d.strict = strict
+	return d
+}
+
+// WithOptions sets the resource limits applied while decoding and returns the decoder
+func (d *ItemDecoder) WithOptions(options DecodeOptions) *ItemDecoder {
+	d.options = options
+	return d
+}
+
+// WithSchemaValidation enables or disables checking the decoded blocks against the schema of their item type
+// and returns the decoder
+func (d *ItemDecoder) WithSchemaValidation(enabled bool) *ItemDecoder {
+	d.validateSchema = enabled
+	return d
+}
+
+// WithUnknownBlocks enables or disables keeping unknown blocks instead of failing and returns the decoder.
+// When enabled, an unknown block and all data after it are returned verbatim as a RawTailBlock,
+// which encodes back to the original bytes.
+func (d *ItemDecoder) WithUnknownBlocks(preserve bool) *ItemDecoder {
+	d.preserveUnknown = preserve
+	return d
 }
 
 // DecodeString decodes an ID string into a series of blocks
 func (d *ItemDecoder) DecodeString(idString string) ([]AnyBlock, error) {
+	// Refuse input which exceeds the limits before allocating anything for it
+	if err := d.options.checkInput(idString); err != nil {
+		return nil, limitDecoderError(err, -1)
+	}
+
 	// Convert string to bytes
 	bytes, err := encoding.DecodeString(idString)
 	if err != nil {
-		return nil, err
+		return nil, codepointError(err)
+	}
+
+	return d.DecodeBytes(bytes)
+}
+
+// DecodeBytes decodes the data of an ID string into a series of blocks
+func (d *ItemDecoder) DecodeBytes(bytes []byte) ([]AnyBlock, error) {
+	if exceeds(len(bytes), d.options.MaxAllocation) {
+		return nil, limitDecoderError(limitError("allocation", len(bytes), d.options.MaxAllocation), -1)
 	}
 
 	// Start by decoding the start block to get the version
 	startBlock, bytesRead, err := DecodeStartBytes(bytes)
 	if err != nil {
-		return nil, err
+		return nil, newBlockError(BlockStartData, 0, err)
 	}
 
 	// Decode the remaining blocks
-	remainingBlocks, err := DecodeAllBlocks(startBlock.Version, bytes[bytesRead:])
+	remainingBlocks, err := d.decodeBlocks(startBlock.Version, bytes[bytesRead:])
 	if err != nil {
-		return nil, err
+		return nil, shiftError(err, bytesRead)
 	}
 
 	// Combine start block with remaining blocks
@@ -87,5 +203,40 @@ func (d *ItemDecoder) DecodeString(idString string) ([]AnyBlock, error) {
 	allBlocks = append(allBlocks, startBlock)
 	allBlocks = append(allBlocks, remainingBlocks...)
 
+	// Check the blocks against the schema of the item type
+	if d.validateSchema {
+		if err := ValidateSchema(allBlocks); err != nil {
+			return nil, schemaDecoderError(err)
+		}
+	}
+
 	return allBlocks, nil
 }
+
+// schemaDecoderError wraps a schema violation found while decoding
+func schemaDecoderError(err error) error {
+	return &encoding.DecoderError{
+		ErrorData: &encoding.DecodeError{
+			Type: encoding.ErrSchemaViolation,
+			Err:  err,
+		},
+		Offset: -1,
+	}
+}
+
+// codepointError wraps an error from converting an ID string to bytes with the offset of the bad codepoint
+func codepointError(err error) error {
+	offset := -1
+	var badCodepoint *encoding.BadCodepointError
+	if errors.As(err, &badCodepoint) {
+		offset = badCodepoint.Index * 2
+	}
+
+	return &encoding.DecoderError{
+		ErrorData: &encoding.DecodeError{
+			Type: encoding.ErrBadCodepoint,
+			Err:  err,
+		},
+		Offset: offset,
+	}
+}
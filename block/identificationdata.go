@@ -1,13 +1,17 @@
 package block
 
 import (
+	"sort"
+
 	"github.com/AevtJJ/idmangler/encoding"
 	"github.com/AevtJJ/idmangler/types"
 )
 
 // IdentificationData represents a block containing item identification data
 type IdentificationData struct {
-	// The identifications
+	// The identifications in the order they are encoded in.
+	// With extended encoding all pre-identified stats are encoded before the rolled ones and
+	// encoding fails with ErrIdentificationOrder otherwise, use Sort to bring the identifications into that order.
 	Identifications []*types.Stat
 	// Whether or not extended encoding is used or to be used for encoding.
 	// If extended encoding is used then all values will have their base values and rolls encoded.
@@ -41,12 +45,27 @@ func (d *IdentificationData) EncodeData(ver types.EncodingVersion, out *[]byte)
 		// Count non pre-identified stats
 		nonPreIdCount := 0
 		preIdCount := 0
-		for _, id := range d.Identifications {
+		for i, id := range d.Identifications {
+			if id == nil {
+				return &encoding.EncodeError{
+					Type: encoding.ErrMissingIdentification,
+					Err:  missingIdentificationError(i),
+				}
+			}
+
 			if !id.PreIdentified() {
 				nonPreIdCount++
-			} else {
-				preIdCount++
+				continue
+			}
+
+			// Pre-identified stats are decoded first, so they have to come first to decode in the same order
+			if d.ExtendedEncoding && nonPreIdCount > 0 {
+				return &encoding.EncodeError{
+					Type: encoding.ErrIdentificationOrder,
+					Err:  identificationOrderError(i),
+				}
 			}
+			preIdCount++
 		}
 
 		// Check for too many identifications
@@ -69,8 +88,8 @@ func (d *IdentificationData) EncodeData(ver types.EncodingVersion, out *[]byte)
 		return d.encodeIndividualIdents(out)
 	default:
 		return &encoding.EncodeError{
-			Type:    encoding.ErrUnknownVersion,
-			Details: ver,
+			Type: encoding.ErrUnknownVersion,
+			Err:  &types.BadVersionError{Version: byte(ver)},
 		}
 	}
 }
@@ -80,29 +99,33 @@ func (d *IdentificationData) encodeIndividualIdents(out *[]byte) error {
 	// Encode pre-identified stats if using extended encoding
 	if d.ExtendedEncoding {
 		// Count pre-identified stats
-		preIdStats := make([]*types.Stat, 0)
+		preIdCount := 0
 		for _, id := range d.Identifications {
 			if id.PreIdentified() {
-				preIdStats = append(preIdStats, id)
+				preIdCount++
 			}
 		}
 
 		// Add count of pre-identified stats
-		*out = append(*out, byte(len(preIdStats)))
+		*out = append(*out, byte(preIdCount))
 
 		// Add pre-identified stats
-		for _, stat := range preIdStats {
+		for _, stat := range d.Identifications {
+			if !stat.PreIdentified() {
+				continue
+			}
+
 			// Add the ID of the stat
 			*out = append(*out, stat.Kind)
 
 			// Add the base value
 			if stat.Base == nil {
 				return &encoding.EncodeError{
-					Type:    encoding.ErrNoBasevalueGiven,
-					Details: stat.Kind,
+					Type: encoding.ErrNoBasevalueGiven,
+					Err:  missingBaseError(stat.Kind),
 				}
 			}
-			*out = append(*out, encoding.EncodeVarInt(int64(*stat.Base))...)
+			*out = encoding.AppendVarInt(*out, int64(*stat.Base))
 		}
 	}
 
@@ -117,11 +140,11 @@ func (d *IdentificationData) encodeIndividualIdents(out *[]byte) error {
 			if d.ExtendedEncoding {
 				if ident.Base == nil {
 					return &encoding.EncodeError{
-						Type:    encoding.ErrNoBasevalueGiven,
-						Details: ident.Kind,
+						Type: encoding.ErrNoBasevalueGiven,
+						Err:  missingBaseError(ident.Kind),
 					}
 				}
-				*out = append(*out, encoding.EncodeVarInt(int64(*ident.Base))...)
+				*out = encoding.AppendVarInt(*out, int64(*ident.Base))
 			}
 
 			// Add roll value
@@ -132,8 +155,31 @@ func (d *IdentificationData) encodeIndividualIdents(out *[]byte) error {
 	return nil
 }
 
+// missingBaseError describes a stat without a base value
+func missingBaseError(kind byte) error {
+	return &encoding.ValueError{
+		Name:   "stat kind",
+		Value:  int64(kind),
+		Reason: "has no base value",
+	}
+}
+
+// identificationOrderError describes a pre-identified stat following a rolled one
+func identificationOrderError(index int) error {
+	return &encoding.ValueError{
+		Name:   "pre-identified stat at index",
+		Value:  int64(index),
+		Reason: "follows a rolled stat",
+	}
+}
+
 // DecodeData decodes data for this block from the given bytes
 func (d *IdentificationData) DecodeData(bytes []byte, ver types.EncodingVersion) (int, error) {
+	return d.decodeDataWith(bytes, ver, nil)
+}
+
+// decodeDataWith decodes data for this block from the given bytes within the given limits
+func (d *IdentificationData) decodeDataWith(bytes []byte, ver types.EncodingVersion, opts *DecodeOptions) (int, error) {
 	if len(bytes) < 2 {
 		return 0, &encoding.DecodeError{
 			Type: encoding.ErrUnexpectedEndOfBytes,
@@ -148,13 +194,20 @@ func (d *IdentificationData) DecodeData(bytes []byte, ver types.EncodingVersion)
 		identCount := int(bytes[0])
 
 		// Second byte is whether extended encoding is used
+		if bytes[1] > 1 {
+			return 1, &encoding.DecodeError{
+				Type: encoding.ErrBadBlockData,
+				Err: &encoding.ValueError{
+					Name:   "extended encoding flag",
+					Value:  int64(bytes[1]),
+					Reason: "is neither 0 nor 1",
+				},
+			}
+		}
 		extendedEncoding := bytes[1] == 1
 
 		bytesUsed = 2
 
-		// Create slice for identifications
-		idents := make([]*types.Stat, 0)
-
 		var preIdCount int
 		if extendedEncoding {
 			// If extended encoding, next byte is the count of pre-identified stats
@@ -165,6 +218,22 @@ func (d *IdentificationData) DecodeData(bytes []byte, ver types.EncodingVersion)
 			}
 			preIdCount = int(bytes[bytesUsed])
 			bytesUsed++
+		}
+
+		// Every stat takes at least two bytes, so don't trust counts the remaining bytes can't hold
+		if err := opts.checkIdentifications(identCount + preIdCount); err != nil {
+			return 0, err
+		}
+		if (identCount+preIdCount)*2 > len(bytes)-bytesUsed {
+			return len(bytes), &encoding.DecodeError{
+				Type: encoding.ErrUnexpectedEndOfBytes,
+			}
+		}
+
+		// Create slice for identifications
+		idents := make([]*types.Stat, 0, identCount+preIdCount)
+
+		if extendedEncoding {
 
 			// Decode pre-identified stats
 			for i := 0; i < preIdCount; i++ {
@@ -178,14 +247,13 @@ func (d *IdentificationData) DecodeData(bytes []byte, ver types.EncodingVersion)
 				bytesUsed++
 
 				// Decode the base value
-				baseVal, n, err := encoding.DecodeVarInt(bytes[bytesUsed:])
+				base, n, err := opts.decodeVarInt32(bytes[bytesUsed:])
 				if err != nil {
 					return bytesUsed, err
 				}
 				bytesUsed += n
 
 				// Create the stat
-				base := int32(baseVal)
 				idents = append(idents, types.NewStat(id, &base, types.NewPreIdentifiedRoll()))
 			}
 		}
@@ -204,13 +272,12 @@ func (d *IdentificationData) DecodeData(bytes []byte, ver types.EncodingVersion)
 			// Decode the base value if extended encoding is used
 			var baseVal *int32
 			if extendedEncoding {
-				val, n, err := encoding.DecodeVarInt(bytes[bytesUsed:])
+				val, n, err := opts.decodeVarInt32(bytes[bytesUsed:])
 				if err != nil {
 					return bytesUsed, err
 				}
 				bytesUsed += n
-				intVal := int32(val)
-				baseVal = &intVal
+				baseVal = &val
 			}
 
 			// Get the roll value
@@ -234,12 +301,26 @@ func (d *IdentificationData) DecodeData(bytes []byte, ver types.EncodingVersion)
 
 	default:
 		return 0, &encoding.DecodeError{
-			Type:    encoding.ErrUnknownVersion,
-			Details: ver,
+			Type: encoding.ErrUnknownVersion,
+			Err:  &types.BadVersionError{Version: byte(ver)},
 		}
 	}
 }
 
+// Sort brings the identifications into the order they are encoded in by moving the pre-identified
+// stats in front of the rolled ones. The order within both groups is kept.
+func (d *IdentificationData) Sort() {
+	SortIdentifications(d.Identifications)
+}
+
+// SortIdentifications moves the pre-identified stats in front of the rolled ones,
+// keeping the order within both groups
+func SortIdentifications(stats []*types.Stat) {
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].PreIdentified() && !stats[j].PreIdentified()
+	})
+}
+
 // NewIdentificationData creates a new IdentificationData block with the given stats and encoding mode
 func NewIdentificationData(identifications []*types.Stat, extendedEncoding bool) *IdentificationData {
 	return &IdentificationData{
@@ -0,0 +1,84 @@
+package block
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+// preIdentified creates a pre-identified stat
+func preIdentified(kind byte, base int32) *types.Stat {
+	return types.NewStat(kind, &base, types.NewPreIdentifiedRoll())
+}
+
+// rolled creates a rolled stat, with a base value for extended encoding if it isn't nil
+func rolled(kind byte, base *int32, roll byte) *types.Stat {
+	return types.NewStat(kind, base, types.NewValueRoll(roll))
+}
+
+func TestIdentificationDataRoundTrip(t *testing.T) {
+	id := byte(BlockIdentificationData)
+	base := int32(64)
+
+	testCases := []struct {
+		block *IdentificationData
+		bytes []byte
+	}{
+		{NewIdentificationData([]*types.Stat{}, false), []byte{id, 0, 0}},
+		{NewIdentificationData([]*types.Stat{rolled(56, nil, 93), rolled(9, nil, 108)}, false), []byte{id, 2, 0, 56, 93, 9, 108}},
+		{NewIdentificationData([]*types.Stat{}, true), []byte{id, 0, 1, 0}},
+		{NewIdentificationData([]*types.Stat{
+			preIdentified(2, -1),
+			preIdentified(4, 2),
+			rolled(1, &base, 50),
+			rolled(3, &base, 60),
+		}, true), []byte{id, 2, 1, 2, 2, 1, 4, 4, 1, 0x80, 0x01, 50, 3, 0x80, 0x01, 60}},
+	}
+
+	for _, tc := range testCases {
+		encoded := roundTripBlock(t, tc.block)
+		if !bytes.Equal(encoded, tc.bytes) {
+			t.Errorf("Incorrect encoding of %+v. Expected %v, got %v", tc.block, tc.bytes, encoded)
+		}
+	}
+}
+
+func TestIdentificationDataInvalid(t *testing.T) {
+	base := int32(10)
+
+	testCases := []struct {
+		name     string
+		stats    []*types.Stat
+		extended bool
+		kind     encoding.ErrorKind
+	}{
+		{"nil stat", []*types.Stat{rolled(1, nil, 50), nil}, false, encoding.ErrMissingIdentification},
+		{"pre-identified after rolled", []*types.Stat{rolled(1, &base, 50), preIdentified(2, 20)}, true, encoding.ErrIdentificationOrder},
+		{"missing base", []*types.Stat{rolled(1, nil, 50)}, true, encoding.ErrNoBasevalueGiven},
+	}
+
+	for _, tc := range testCases {
+		var out []byte
+		if err := NewIdentificationData(tc.stats, tc.extended).Encode(types.Version1, &out); !errors.Is(err, tc.kind) {
+			t.Errorf("%s: Expected %v, got %v", tc.name, tc.kind, err)
+		}
+	}
+}
+
+func TestIdentificationDataSort(t *testing.T) {
+	base := int32(10)
+	block := NewIdentificationData([]*types.Stat{rolled(1, &base, 50), preIdentified(2, 20), rolled(3, &base, 60), preIdentified(4, 40)}, true)
+
+	block.Sort()
+	expected := []byte{2, 4, 1, 3}
+	for i, stat := range block.Identifications {
+		if stat.Kind != expected[i] {
+			t.Errorf("Expected stat %d at index %d, got %d", expected[i], i, stat.Kind)
+		}
+	}
+
+	roundTripBlock(t, block)
+}
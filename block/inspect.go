@@ -0,0 +1,536 @@
+package block
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+// TraceNode describes a block or a field of a block together with the bytes it was decoded from
+type TraceNode struct {
+	// Name is the name of the block or field
+	Name string
+	// Start is the offset of the first byte within the decoded data
+	Start int
+	// End is the offset after the last byte within the decoded data
+	End int
+	// BitOffset is the offset of the first bit within the first byte for fields which are not byte aligned
+	BitOffset int
+	// BitLength is the number of bits of fields which are not byte aligned, 0 for whole bytes
+	BitLength int
+	// Raw holds the bytes the node was decoded from
+	Raw []byte
+	// Value is the decoded value, the block itself for block nodes
+	Value interface{}
+	// Children are the fields of a block or the parts of a field
+	Children []*TraceNode
+	// Err is the error which stopped decoding this node, nil if it was decoded
+	Err error
+}
+
+// FirstCodepoint returns the index of the codepoint in the ID string holding the first byte of the node
+func (n *TraceNode) FirstCodepoint() int {
+	return encoding.CodepointIndex(n.Start)
+}
+
+// LastCodepoint returns the index of the codepoint in the ID string holding the last byte of the node
+func (n *TraceNode) LastCodepoint() int {
+	if n.End <= n.Start {
+		return n.FirstCodepoint()
+	}
+	return encoding.CodepointIndex(n.End - 1)
+}
+
+// String returns a single line description of the node
+func (n *TraceNode) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[%d:%d] cp %d-%d", n.Start, n.End, n.FirstCodepoint(), n.LastCodepoint())
+	if n.BitLength > 0 {
+		fmt.Fprintf(&sb, " bits %d+%d", n.BitOffset, n.BitLength)
+	}
+	fmt.Fprintf(&sb, " %s", n.Name)
+	if n.Value != nil {
+		if _, ok := n.Value.(AnyBlock); !ok {
+			fmt.Fprintf(&sb, " = %v", n.Value)
+		}
+	}
+	fmt.Fprintf(&sb, " (% x)", n.Raw)
+	if n.Err != nil {
+		fmt.Fprintf(&sb, " error: %v", n.Err)
+	}
+	return sb.String()
+}
+
+// Trace is the annotated result of decoding an ID string, accounting for every decoded byte
+type Trace struct {
+	// Bytes holds the decoded data of the ID string
+	Bytes []byte
+	// Blocks holds a node for every block in the order they were decoded.
+	// Bytes after the EndData block are described by a final "TrailingBytes" node.
+	Blocks []*TraceNode
+	// Err is the error which stopped decoding, nil if the whole string was decoded
+	Err error
+}
+
+// String returns the trace as an indented tree with one node per line
+func (t *Trace) String() string {
+	var sb strings.Builder
+	var write func(nodes []*TraceNode, depth int)
+	write = func(nodes []*TraceNode, depth int) {
+		for _, node := range nodes {
+			sb.WriteString(strings.Repeat("  ", depth))
+			sb.WriteString(node.String())
+			sb.WriteByte('\n')
+			write(node.Children, depth+1)
+		}
+	}
+	write(t.Blocks, 0)
+
+	if t.Err != nil {
+		fmt.Fprintf(&sb, "error: %v\n", t.Err)
+	}
+	return sb.String()
+}
+
+// Inspect decodes an ID string into a trace of blocks and their fields with the byte spans
+// and codepoints they were decoded from. Decoding stops at the first error, which is recorded
+// in the trace together with everything decoded before it. The block which failed is traced
+// up to the field holding the offending byte, the bytes after it form an "unparsed" node.
+func Inspect(idString string) *Trace {
+	trace := &Trace{Blocks: make([]*TraceNode, 0)}
+
+	// Convert the string to bytes, keeping everything before a bad codepoint
+	bytes := make([]byte, 0, encoding.DecodedLength(idString))
+	index := 0
+	for _, c := range idString {
+		decoded, err := encoding.DecodeChar(c)
+		if err != nil {
+			if badCodepoint, ok := err.(*encoding.BadCodepointError); ok {
+				badCodepoint.Index = index
+			}
+			trace.Err = codepointError(err)
+			break
+		}
+		bytes = append(bytes, decoded...)
+		index++
+	}
+	trace.Bytes = bytes
+	t := &tracer{bytes: bytes}
+
+	// Decode the start block to get the version
+	startBlock, bytesUsed, err := DecodeStartBytes(bytes)
+	if err != nil {
+		if trace.Err == nil {
+			trace.Err = newBlockError(BlockStartData, 0, err)
+		}
+		trace.Blocks = append(trace.Blocks, t.failed(0, BlockStartData, true, trace.Err))
+		return trace
+	}
+	trace.Blocks = append(trace.Blocks, t.decoded(0, startBlock, bytesUsed))
+
+	opts := DefaultDecodeOptions()
+	for bytesUsed < len(bytes) {
+		block, n, err := decodeBlock(startBlock.Version, bytes[bytesUsed:], &opts)
+		if err != nil {
+			id, idErr := DataBlockIDFromByte(bytes[bytesUsed])
+			err = shiftError(err, bytesUsed)
+			if trace.Err == nil {
+				trace.Err = err
+			}
+			trace.Blocks = append(trace.Blocks, t.failed(bytesUsed, id, idErr == nil, err))
+			return trace
+		}
+
+		trace.Blocks = append(trace.Blocks, t.decoded(bytesUsed, block, n))
+		bytesUsed += n
+
+		if block.AsID() == BlockEndData {
+			break
+		}
+	}
+
+	if bytesUsed < len(bytes) {
+		trace.Blocks = append(trace.Blocks, &TraceNode{
+			Name:  "TrailingBytes",
+			Start: bytesUsed,
+			End:   len(bytes),
+			Raw:   bytes[bytesUsed:],
+			Value: len(bytes) - bytesUsed,
+		})
+	}
+
+	return trace
+}
+
+// TraceMismatchError reports a block whose traced fields do not cover the bytes the decoder read for it
+type TraceMismatchError struct {
+	// Block is the ID of the block
+	Block DataBlockID
+	// Traced is the number of bytes covered by the traced fields
+	Traced int
+	// Decoded is the number of bytes read by the decoder
+	Decoded int
+}
+
+// Error returns the error message for a mismatching trace
+func (e *TraceMismatchError) Error() string {
+	return fmt.Sprintf("traced fields of %s cover %d bytes, but %d bytes were decoded", e.Block, e.Traced, e.Decoded)
+}
+
+// tracer walks the bytes of a block field by field, reading the layout from the bytes themselves
+// so that blocks which failed to decode can be traced up to the offending field
+type tracer struct {
+	bytes []byte
+	pos   int
+	// stop is the offset of the last byte a field may start at
+	stop int
+	// truncated is set once a field could not be read, no further fields are added after it
+	truncated bool
+}
+
+// decoded traces a block which was decoded from n bytes starting at the given offset.
+// If the traced fields don't match the bytes read by the decoder the node records a TraceMismatchError.
+func (t *tracer) decoded(start int, b AnyBlock, n int) *TraceNode {
+	node := t.block(start, b.AsID(), true, len(t.bytes))
+	node.Value = b
+
+	if node.End-node.Start != n || t.truncated {
+		node.Err = &TraceMismatchError{Block: b.AsID(), Traced: node.End - node.Start, Decoded: n}
+	}
+	return node
+}
+
+// failed traces a block starting at the given offset which failed to decode with the given error.
+// The field holding the offending byte records the error and the rest of the data forms an "unparsed" node.
+func (t *tracer) failed(start int, id DataBlockID, known bool, err error) *TraceNode {
+	offset := len(t.bytes)
+	var decoderErr *encoding.DecoderError
+	// Errors at the start of a known block don't point at a field, so all of its fields are traced
+	if errors.As(err, &decoderErr) && (decoderErr.Offset > start || !known && decoderErr.Offset == start) {
+		offset = decoderErr.Offset
+	}
+
+	node := t.block(start, id, known, offset)
+	node.Err = err
+	if leaf := leafAt(node, offset); leaf != nil {
+		leaf.Err = err
+	}
+
+	if t.pos < len(t.bytes) {
+		node.Children = append(node.Children, &TraceNode{
+			Name:  "unparsed",
+			Start: t.pos,
+			End:   len(t.bytes),
+			Raw:   t.bytes[t.pos:],
+		})
+		t.pos = len(t.bytes)
+		t.close(node)
+	}
+	return node
+}
+
+// leafAt returns the innermost node below the given node holding the byte at the given offset
+func leafAt(node *TraceNode, offset int) *TraceNode {
+	for _, child := range node.Children {
+		if child.Start <= offset && offset < child.End {
+			if leaf := leafAt(child, offset); leaf != nil {
+				return leaf
+			}
+			return child
+		}
+	}
+	return nil
+}
+
+// block traces the fields of a block with the given ID starting at the given offset.
+// No field starts after the stop offset. Unknown blocks only get their ID traced.
+func (t *tracer) block(start int, id DataBlockID, known bool, stop int) *TraceNode {
+	t.pos = start
+	t.stop = stop
+	t.truncated = false
+
+	node := &TraceNode{Name: "UnknownBlock", Start: start}
+	if !known {
+		t.byteField(node, "id", nil)
+		t.close(node)
+		return node
+	}
+
+	node.Name = id.String()
+	t.byteField(node, "id", func(v byte) interface{} { return DataBlockID(v) })
+
+	switch id {
+	case BlockStartData:
+		t.byteField(node, "version", func(v byte) interface{} {
+			if v == legacyVersionByte {
+				return "legacy " + types.Version1.String()
+			}
+			return types.EncodingVersion(v)
+		})
+	case BlockTypeData:
+		t.byteField(node, "type", func(v byte) interface{} { return types.ItemType(v) })
+	case BlockNameData:
+		t.traceName(node)
+	case BlockIdentificationData:
+		t.traceIdentifications(node)
+	case BlockPowderData:
+		t.tracePowders(node)
+	case BlockRerollData:
+		t.byteField(node, "rerolls", nil)
+	case BlockShinyData:
+		t.byteField(node, "stat", nil)
+		t.varIntField(node, "value")
+	case BlockCraftedGearType:
+		t.byteField(node, "gear type", func(v byte) interface{} { return types.CraftedGearType(v) })
+	case BlockDurabilityData:
+		t.byteField(node, "effect strength", nil)
+		t.varIntField(node, "max")
+		t.varIntField(node, "current")
+	case BlockRequirementsData:
+		t.byteField(node, "level", nil)
+		t.byteField(node, "class", func(v byte) interface{} {
+			if v == 0 {
+				return "none"
+			}
+			return types.ClassType(v)
+		})
+		count := t.byteField(node, "count", nil)
+		for i := 0; i < int(count) && !t.truncated; i++ {
+			entry := t.group(node, fmt.Sprintf("skill[%d]", i))
+			t.byteField(entry, "skill", func(v byte) interface{} { return types.SkillType(v) })
+			if points, ok := t.varIntField(entry, "points"); ok {
+				entry.Value = points
+			}
+			t.close(entry)
+		}
+	case BlockDamageData:
+		t.byteField(node, "attack speed", func(v byte) interface{} { return types.AttackSpeed(v) })
+		count := t.byteField(node, "count", nil)
+		for i := 0; i < int(count) && !t.truncated; i++ {
+			entry := t.group(node, fmt.Sprintf("damage[%d]", i))
+			t.byteField(entry, "element", func(v byte) interface{} {
+				if v == neutralDamageID {
+					return "Neutral"
+				}
+				return types.Element(v)
+			})
+			min, minOk := t.varIntField(entry, "min")
+			max, maxOk := t.varIntField(entry, "max")
+			if minOk && maxOk {
+				entry.Value = types.NewElementalRange(int32(min), int32(max))
+			}
+			t.close(entry)
+		}
+	case BlockDefenseData:
+		t.varIntField(node, "health")
+		count := t.byteField(node, "count", nil)
+		for i := 0; i < int(count) && !t.truncated; i++ {
+			entry := t.group(node, fmt.Sprintf("defense[%d]", i))
+			t.byteField(entry, "element", func(v byte) interface{} { return types.Element(v) })
+			if value, ok := t.varIntField(entry, "value"); ok {
+				entry.Value = value
+			}
+			t.close(entry)
+		}
+	case BlockCraftedIdentificationData:
+		count := t.byteField(node, "count", nil)
+		for i := 0; i < int(count) && !t.truncated; i++ {
+			entry := t.group(node, fmt.Sprintf("stat[%d]", i))
+			t.byteField(entry, "kind", nil)
+			if max, ok := t.varIntField(entry, "max"); ok {
+				entry.Value = max
+			}
+			t.close(entry)
+		}
+	case BlockCraftedConsumableTypeData:
+		t.byteField(node, "consumable type", func(v byte) interface{} { return types.ConsumableType(v) })
+	case BlockUsesData:
+		t.byteField(node, "current", nil)
+		t.byteField(node, "max", nil)
+	case BlockEffectsData:
+		count := t.byteField(node, "count", nil)
+		for i := 0; i < int(count) && !t.truncated; i++ {
+			entry := t.group(node, fmt.Sprintf("effect[%d]", i))
+			t.byteField(entry, "kind", func(v byte) interface{} { return types.EffectType(v) })
+			if value, ok := t.varIntField(entry, "value"); ok {
+				entry.Value = value
+			}
+			t.close(entry)
+		}
+	}
+
+	t.close(node)
+	return node
+}
+
+// field adds a node for the next n bytes to the parent.
+// Returns nil and marks the trace as truncated if the bytes are missing or the field starts after the stop offset.
+func (t *tracer) field(parent *TraceNode, name string, n int, value interface{}) *TraceNode {
+	if t.truncated || t.pos > t.stop || t.pos+n > len(t.bytes) {
+		t.truncated = true
+		return nil
+	}
+
+	node := &TraceNode{
+		Name:  name,
+		Start: t.pos,
+		End:   t.pos + n,
+		Raw:   t.bytes[t.pos : t.pos+n],
+		Value: value,
+	}
+	parent.Children = append(parent.Children, node)
+	t.pos += n
+	return node
+}
+
+// byteField adds a node for the next byte to the parent, value converts the byte to its decoded value.
+// Returns 0 if the byte could not be read.
+func (t *tracer) byteField(parent *TraceNode, name string, value func(b byte) interface{}) byte {
+	if t.pos >= len(t.bytes) {
+		t.truncated = true
+		return 0
+	}
+
+	b := t.bytes[t.pos]
+	var decoded interface{} = b
+	if value != nil {
+		decoded = value(b)
+	}
+	if t.field(parent, name, 1, decoded) == nil {
+		return 0
+	}
+	return b
+}
+
+// varIntField adds a node for the varint at the current position to the parent.
+// A varint which is cut off by the end of the data or invalid gets a node without a value.
+// Returns false if the varint could not be decoded.
+func (t *tracer) varIntField(parent *TraceNode, name string) (int64, bool) {
+	// The varint ends with the first byte without the continuation bit
+	n := 0
+	for t.pos+n < len(t.bytes) && n < encoding.MaxVarIntLength {
+		n++
+		if t.bytes[t.pos+n-1]&0x80 == 0 {
+			break
+		}
+	}
+
+	value, _, err := encoding.DecodeVarInt(t.bytes[t.pos : t.pos+n])
+	var decoded interface{}
+	if err == nil {
+		decoded = value
+	}
+	if t.field(parent, name, n, decoded) == nil || err != nil {
+		t.truncated = true
+		return 0, false
+	}
+	return value, true
+}
+
+// group adds a node starting at the current position to the parent, its fields are added to it
+// and close sets its end
+func (t *tracer) group(parent *TraceNode, name string) *TraceNode {
+	node := &TraceNode{Name: name, Start: t.pos}
+	parent.Children = append(parent.Children, node)
+	return node
+}
+
+// close ends a node at the current position
+func (t *tracer) close(node *TraceNode) {
+	node.End = t.pos
+	node.Raw = t.bytes[node.Start:node.End]
+}
+
+// traceName adds the fields of a NameData block
+func (t *tracer) traceName(node *TraceNode) {
+	n := 0
+	for t.pos+n < len(t.bytes) && t.bytes[t.pos+n] != 0 {
+		n++
+	}
+
+	t.field(node, "name", n, string(t.bytes[t.pos:t.pos+n]))
+	t.field(node, "terminator", 1, nil)
+}
+
+// traceIdentifications adds the fields of an IdentificationData block
+func (t *tracer) traceIdentifications(node *TraceNode) {
+	count := t.byteField(node, "count", nil)
+	extended := t.byteField(node, "extended", nil) == 1
+
+	if extended {
+		preIdCount := t.byteField(node, "pre-identified count", nil)
+		for i := 0; i < int(preIdCount) && !t.truncated; i++ {
+			entry := t.group(node, fmt.Sprintf("pre-identified[%d]", i))
+			kind := t.byteField(entry, "kind", nil)
+			base, ok := t.varIntField(entry, "base")
+			t.close(entry)
+
+			if ok {
+				baseVal := int32(base)
+				entry.Value = types.NewStat(kind, &baseVal, types.NewPreIdentifiedRoll())
+			}
+		}
+	}
+
+	for i := 0; i < int(count) && !t.truncated; i++ {
+		entry := t.group(node, fmt.Sprintf("stat[%d]", i))
+		kind := t.byteField(entry, "kind", nil)
+		var base *int32
+		if extended {
+			if baseVal, ok := t.varIntField(entry, "base"); ok {
+				base32 := int32(baseVal)
+				base = &base32
+			}
+		}
+		roll := t.byteField(entry, "roll", nil)
+		t.close(entry)
+
+		if !t.truncated {
+			entry.Value = types.NewStat(kind, base, types.NewValueRoll(roll))
+		}
+	}
+}
+
+// tracePowders adds the fields of a PowderData block including a node for every 5 bit powder cell
+func (t *tracer) tracePowders(node *TraceNode) {
+	t.byteField(node, "slots", nil)
+	count := int(t.byteField(node, "count", nil))
+
+	start := t.pos
+	size := (count*5 + 7) / 8
+	if t.truncated || start > t.stop || start+size > len(t.bytes) {
+		t.truncated = true
+		return
+	}
+
+	data := t.bytes[start : start+size]
+	cells := t.group(node, "powders")
+	for i := 0; i < count; i++ {
+		bit := i * 5
+		first := start + bit/8
+		last := start + (bit+4)/8
+
+		cell := &TraceNode{
+			Name:      fmt.Sprintf("powder[%d]", i),
+			Start:     first,
+			End:       last + 1,
+			BitOffset: bit % 8,
+			BitLength: 5,
+			Raw:       t.bytes[first : last+1],
+		}
+
+		value := powderCell(data, i)
+		if value == 0 {
+			cell.Value = "empty"
+		} else if powder, _, err := powderFromCell(value); err == nil {
+			cell.Value = powder
+		}
+		cells.Children = append(cells.Children, cell)
+	}
+
+	t.pos += len(data)
+	t.close(cells)
+}
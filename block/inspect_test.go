@@ -0,0 +1,173 @@
+package block
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+// checkSpans checks that the children of a node cover it byte for byte
+func checkSpans(t *testing.T, node *TraceNode) {
+	if len(node.Children) == 0 || node.Children[0].BitLength > 0 {
+		return
+	}
+
+	pos := node.Start
+	for _, child := range node.Children {
+		if child.Start != pos {
+			t.Errorf("%s: expected %s to start at byte %d, got %d", node.Name, child.Name, pos, child.Start)
+		}
+		pos = child.End
+		checkSpans(t, child)
+	}
+
+	if pos != node.End {
+		t.Errorf("%s: expected fields to end at byte %d, got %d", node.Name, node.End, pos)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	for _, seed := range seedItems(t) {
+		trace := Inspect(encoding.EncodeString(seed))
+		if trace.Err != nil {
+			t.Errorf("Unexpected error inspecting %v: %v", seed, trace.Err)
+			continue
+		}
+
+		pos := 0
+		for _, node := range trace.Blocks {
+			if node.Start != pos {
+				t.Errorf("Expected %s to start at byte %d, got %d", node.Name, pos, node.Start)
+			}
+			pos = node.End
+			checkSpans(t, node)
+		}
+
+		if pos != len(seed) {
+			t.Errorf("Expected the blocks to cover %d bytes, got %d", len(seed), pos)
+		}
+	}
+}
+
+func TestInspectFields(t *testing.T) {
+	bytes := []byte{0, 1, 1, 0, 2, 'A', 0, 3, 1, 1, 1, 2, 0x80, 0x01, 10, 6, 50, 4, 2, 2, 0b00110_001, 0b11_000000, 255}
+	trace := Inspect(encoding.EncodeString(bytes))
+	if trace.Err != nil {
+		t.Fatalf("Unexpected error: %v", trace.Err)
+	}
+
+	idents := trace.Blocks[3]
+	base := idents.Children[4].Children[1]
+	if base.Name != "base" || base.Start != 12 || base.End != 14 || base.Value != int64(64) {
+		t.Errorf("Expected the pre-identified base varint at bytes 12-14 with value 64, got %v", base)
+	}
+	if base.FirstCodepoint() != 6 || base.LastCodepoint() != 6 {
+		t.Errorf("Expected the base varint in codepoint 6, got %d-%d", base.FirstCodepoint(), base.LastCodepoint())
+	}
+
+	cells := trace.Blocks[4].Children[3].Children
+	expected := []types.Powder{{Element: types.Earth, Tier: 6}, {Element: types.Thunder, Tier: 1}}
+	for i, cell := range cells {
+		if cell.Value != expected[i] || cell.BitOffset != (i*5)%8 || cell.BitLength != 5 {
+			t.Errorf("Expected powder %v at bit %d, got %v", expected[i], (i*5)%8, cell)
+		}
+	}
+	if cells[1].Start != 20 || cells[1].End != 22 {
+		t.Errorf("Expected the second powder to span bytes 20-22, got %d-%d", cells[1].Start, cells[1].End)
+	}
+}
+
+func TestInspectFailure(t *testing.T) {
+	bytes := []byte{0, 1, 1, 0, 2, 'A', 0, 11, 3, 1, 9}
+	trace := Inspect(encoding.EncodeString(bytes))
+
+	if !errors.Is(trace.Err, encoding.ErrBadElement) {
+		t.Fatalf("Expected %v, got %v", encoding.ErrBadElement, trace.Err)
+	}
+
+	failed := trace.Blocks[len(trace.Blocks)-1]
+	if failed.Name != "DefenseData" || failed.Start != 7 || failed.Err == nil {
+		t.Errorf("Expected a failed DefenseData node at byte 7, got %v", failed)
+	}
+	element := failed.Children[3].Children[0]
+	if element.Name != "element" || element.Start != 10 || element.Err == nil {
+		t.Errorf("Expected the error on the element at byte 10, got %v", element)
+	}
+}
+
+// TestInspectMatchesDecoder checks the traced fields of every block type against the bytes read by its decoder
+func TestInspectMatchesDecoder(t *testing.T) {
+	seen := make(map[DataBlockID]bool)
+	seeds := append(seedItems(t), []byte{0, 1, 1, 0, 2, 'A', 0, 3, 1, 1, 1, 2, 0x80, 0x01, 10, 6, 50, 255})
+
+	for _, seed := range seeds {
+		trace := Inspect(encoding.EncodeString(seed))
+		if trace.Err != nil {
+			t.Errorf("Unexpected error inspecting %v: %v", seed, trace.Err)
+			continue
+		}
+
+		for _, node := range trace.Blocks {
+			block := node.Value.(AnyBlock)
+			seen[block.AsID()] = true
+
+			_, n, err := DecodeBlock(types.Version1, seed[node.Start:])
+			if block.AsID() == BlockStartData {
+				_, n, err = DecodeStartBytes(seed)
+			}
+			if err != nil {
+				t.Errorf("%s: Unexpected error decoding: %v", node.Name, err)
+			}
+			if node.End-node.Start != n || node.Err != nil {
+				t.Errorf("%s: Expected the fields to cover %d bytes, got %d: %v", node.Name, n, node.End-node.Start, node.Err)
+			}
+		}
+	}
+
+	for b := 0; b < 256; b++ {
+		if id, err := DataBlockIDFromByte(byte(b)); err == nil && !seen[id] {
+			t.Errorf("Expected %s to be traced", id)
+		}
+	}
+}
+
+func TestInspectFailedFields(t *testing.T) {
+	testCases := []struct {
+		name   string
+		bytes  []byte
+		block  string
+		fields []string
+		kind   error
+	}{
+		{"bad class", []byte{0, 1, 1, 3, 9, 60, 9, 0, 255}, "RequirementsData", []string{"id", "level", "class", "unparsed"}, encoding.ErrBadClassType},
+		{"unterminated varint", []byte{0, 1, 1, 0, 11, 0x80, 0x80}, "DefenseData", []string{"id", "health"}, encoding.ErrUnexpectedEndOfBytes},
+		{"unknown block", []byte{0, 1, 1, 0, 42, 1, 2}, "UnknownBlock", []string{"id", "unparsed"}, encoding.ErrUnknownBlock},
+		{"bad version", []byte{0, 9}, "StartData", []string{"id", "version"}, encoding.ErrUnknownVersion},
+	}
+
+	for _, tc := range testCases {
+		trace := Inspect(encoding.EncodeString(tc.bytes))
+		if !errors.Is(trace.Err, tc.kind) {
+			t.Errorf("%s: Expected %v, got %v", tc.name, tc.kind, trace.Err)
+			continue
+		}
+
+		failed := trace.Blocks[len(trace.Blocks)-1]
+		if failed.Name != tc.block || failed.Err == nil || failed.End != len(tc.bytes) {
+			t.Errorf("%s: Expected a failed %s node up to byte %d, got %v", tc.name, tc.block, len(tc.bytes), failed)
+			continue
+		}
+		checkSpans(t, failed)
+
+		names := make([]string, 0, len(failed.Children))
+		for _, child := range failed.Children {
+			names = append(names, child.Name)
+		}
+		if fmt.Sprint(names) != fmt.Sprint(tc.fields) {
+			t.Errorf("%s: Expected fields %v, got %v", tc.name, tc.fields, names)
+		}
+	}
+}
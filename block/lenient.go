@@ -0,0 +1,174 @@
+package block
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AevtJJ/idmangler/encoding"
+)
+
+// WarningKind represents the kind of a problem found during lenient decoding
+type WarningKind int
+
+const (
+	// WarnMissingEndData indicates that the data ended without an EndData block
+	WarnMissingEndData WarningKind = iota
+	// WarnTrailingBytes indicates that there are bytes after the EndData block
+	WarnTrailingBytes
+	// WarnUnknownBlock indicates that an unknown block ID was encountered
+	WarnUnknownBlock
+	// WarnBadCodepoint indicates that the string contains a codepoint outside of the encoding scheme
+	WarnBadCodepoint
+	// WarnSchemaViolation indicates that the decoded blocks do not match the schema of the item type
+	WarnSchemaViolation
+)
+
+// String returns the string representation of a WarningKind
+func (k WarningKind) String() string {
+	switch k {
+	case WarnMissingEndData:
+		return "MissingEndData"
+	case WarnTrailingBytes:
+		return "TrailingBytes"
+	case WarnUnknownBlock:
+		return "UnknownBlock"
+	case WarnBadCodepoint:
+		return "BadCodepoint"
+	case WarnSchemaViolation:
+		return "SchemaViolation"
+	default:
+		return fmt.Sprintf("Unknown(%d)", k)
+	}
+}
+
+// DecodeWarning represents a problem found during lenient decoding
+type DecodeWarning struct {
+	// Kind is the kind of the problem
+	Kind WarningKind
+	// Offset is the byte offset at which the problem was found
+	Offset int
+	// Message describes the problem
+	Message string
+}
+
+// String returns a string representation of the warning
+func (w DecodeWarning) String() string {
+	return fmt.Sprintf("%s at byte %d: %s", w.Kind, w.Offset, w.Message)
+}
+
+// DecodeResult holds the outcome of decoding an ID string in lenient mode
+type DecodeResult struct {
+	// Blocks contains every block decoded before decoding stopped
+	Blocks []AnyBlock
+	// Err is the error which stopped decoding, nil if all data was decoded
+	Err error
+	// FailOffset is the byte offset of the block that failed to decode, -1 if decoding did not fail
+	FailOffset int
+	// FailBlock is the ID of the block that failed to decode, nil if decoding did not fail,
+	// the start block or the block ID itself was invalid or the error did not happen within a block
+	FailBlock *DataBlockID
+	// Warnings is a list of problems found during decoding
+	Warnings []DecodeWarning
+}
+
+// Complete returns whether the whole string was decoded without errors or warnings
+func (r *DecodeResult) Complete() bool {
+	return r.Err == nil && len(r.Warnings) == 0
+}
+
+// warn adds a warning to the result
+func (r *DecodeResult) warn(kind WarningKind, offset int, format string, args ...interface{}) {
+	r.Warnings = append(r.Warnings, DecodeWarning{
+		Kind:    kind,
+		Offset:  offset,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// fail records the error which stopped decoding at the given offset
+func (r *DecodeResult) fail(offset int, err error) {
+	r.Err = err
+	r.FailOffset = offset
+	var decoderErr *encoding.DecoderError
+	if errors.As(err, &decoderErr) && decoderErr.During != nil {
+		id := DataBlockID(*decoderErr.During)
+		r.FailBlock = &id
+	}
+}
+
+// DecodeStringLenient decodes an ID string without giving up on the first problem.
+// It returns every block decoded before a failure together with the failing offset and block,
+// and warnings for recoverable problems such as a missing EndData block or trailing bytes.
+// Blocks are decoded with the same limits and strict rules as DecodeString.
+func (d *ItemDecoder) DecodeStringLenient(idString string) *DecodeResult {
+	result := &DecodeResult{
+		Blocks:     make([]AnyBlock, 0),
+		FailOffset: -1,
+	}
+
+	// Refuse input which exceeds the limits before allocating anything for it
+	if err := d.options.checkInput(idString); err != nil {
+		result.Err = limitDecoderError(err, -1)
+		return result
+	}
+
+	// Convert the string to bytes, keeping everything before a bad codepoint
+	bytes := make([]byte, 0, encoding.DecodedLength(idString))
+	for _, c := range idString {
+		decoded, err := encoding.DecodeChar(c)
+		if err != nil {
+			result.warn(WarnBadCodepoint, len(bytes), "%v, ignoring the rest of the string", err)
+			break
+		}
+		bytes = append(bytes, decoded...)
+	}
+	if exceeds(len(bytes), d.options.MaxAllocation) {
+		result.Err = limitDecoderError(limitError("allocation", len(bytes), d.options.MaxAllocation), -1)
+		return result
+	}
+
+	// Start by decoding the start block to get the version, no block failed if there is none
+	startBlock, bytesRead, err := DecodeStartBytes(bytes)
+	if err != nil {
+		result.Err = newBlockError(BlockStartData, 0, err)
+		result.FailOffset = 0
+		return result
+	}
+	result.Blocks = append(result.Blocks, startBlock)
+
+	// Decode the remaining blocks, keeping those decoded before a failure
+	ended := false
+	n, _, err := d.walkBlocks(startBlock.Version, bytes[bytesRead:], func(block AnyBlock, offset int) bool {
+		tail, isTail := block.(*RawTailBlock)
+		if isTail {
+			result.warn(WarnUnknownBlock, bytesRead+offset, "unknown block id %d, kept %d bytes verbatim", tail.ID, 1+len(tail.Data))
+		}
+		ended = isTail || block.AsID() == BlockEndData
+		result.Blocks = append(result.Blocks, block)
+		return true
+	})
+	bytesUsed := bytesRead + n
+	if err != nil {
+		if errors.Is(err, encoding.ErrUnknownBlock) {
+			result.warn(WarnUnknownBlock, bytesUsed, "unknown block id %d", bytes[bytesUsed])
+		}
+		result.fail(bytesUsed, shiftError(err, bytesRead))
+		return result
+	}
+
+	if ended && bytesUsed < len(bytes) {
+		result.warn(WarnTrailingBytes, bytesUsed, "%d bytes after EndData", len(bytes)-bytesUsed)
+	}
+	if !ended {
+		result.warn(WarnMissingEndData, bytesUsed, "data ended without an EndData block")
+	}
+
+	// Check the blocks against the schema of the item type
+	if d.validateSchema {
+		if err := ValidateSchema(result.Blocks); err != nil {
+			result.warn(WarnSchemaViolation, bytesUsed, "%v", err)
+		}
+	}
+
+	return result
+}
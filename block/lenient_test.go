@@ -0,0 +1,91 @@
+package block
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AevtJJ/idmangler/encoding"
+)
+
+func TestDecodeStringLenient(t *testing.T) {
+	valid := []byte{0, 1, 1, 0, 2, 'A', 0, 5, 2, 255}
+
+	testCases := []struct {
+		name       string
+		bytes      []byte
+		blocks     int
+		failOffset int
+		warnings   []WarningKind
+	}{
+		{"valid", valid, 5, -1, nil},
+		{"missing end", valid[:9], 4, -1, []WarningKind{WarnMissingEndData}},
+		{"trailing bytes", append(append([]byte{}, valid...), 1, 2), 5, -1, []WarningKind{WarnTrailingBytes}},
+		{"truncated block", valid[:5], 2, 4, nil},
+		{"unknown block", []byte{0, 1, 1, 0, 2, 'A', 0, 200, 1}, 3, 7, []WarningKind{WarnUnknownBlock}},
+		{"no start", []byte{1, 0}, 0, 0, nil},
+	}
+
+	for _, tc := range testCases {
+		result := NewItemDecoder().DecodeStringLenient(encoding.EncodeString(tc.bytes))
+
+		if len(result.Blocks) != tc.blocks {
+			t.Errorf("%s: expected %d blocks, got %d", tc.name, tc.blocks, len(result.Blocks))
+		}
+
+		if result.FailOffset != tc.failOffset {
+			t.Errorf("%s: expected fail offset %d, got %d (%v)", tc.name, tc.failOffset, result.FailOffset, result.Err)
+		}
+
+		if (result.Err != nil) != (tc.failOffset >= 0) {
+			t.Errorf("%s: unexpected error state: %v", tc.name, result.Err)
+		}
+
+		if len(result.Warnings) != len(tc.warnings) {
+			t.Errorf("%s: expected warnings %v, got %v", tc.name, tc.warnings, result.Warnings)
+			continue
+		}
+		for i, kind := range tc.warnings {
+			if result.Warnings[i].Kind != kind {
+				t.Errorf("%s: expected warning %v, got %v", tc.name, kind, result.Warnings[i])
+			}
+		}
+	}
+}
+
+func TestDecodeStringLenientFailBlock(t *testing.T) {
+	powder := BlockPowderData
+	name := BlockNameData
+
+	testCases := []struct {
+		name      string
+		decoder   *ItemDecoder
+		bytes     []byte
+		kind      encoding.ErrorKind
+		failBlock *DataBlockID
+	}{
+		{"truncated powder", NewItemDecoder(), []byte{0, 1, 1, 0, 2, 'A', 0, 4, 1}, encoding.ErrUnexpectedEndOfBytes, &powder},
+		{"no start", NewItemDecoder(), []byte{1, 0, 255}, encoding.ErrNoStartBlockFound, nil},
+		{"bad version", NewItemDecoder(), []byte{0, 4, 1, 0, 255}, encoding.ErrUnknownVersion, nil},
+		{"strict duplicate", NewItemDecoder().WithStrict(true), []byte{0, 1, 1, 0, 2, 'A', 0, 2, 'B', 0, 255}, encoding.ErrDuplicateBlock, &name},
+		{"strict missing end", NewItemDecoder().WithStrict(true), []byte{0, 1, 1, 0, 2, 'A', 0}, encoding.ErrMissingEndData, nil},
+		{"block limit", NewItemDecoder().WithOptions(DecodeOptions{MaxBlocks: 2}), []byte{0, 1, 1, 0, 2, 'A', 0, 255}, encoding.ErrLimitExceeded, nil},
+	}
+
+	for _, tc := range testCases {
+		result := tc.decoder.DecodeStringLenient(encoding.EncodeString(tc.bytes))
+
+		if !errors.Is(result.Err, tc.kind) {
+			t.Errorf("%s: Expected %v, got %v", tc.name, tc.kind, result.Err)
+		}
+
+		if (result.FailBlock == nil) != (tc.failBlock == nil) || (tc.failBlock != nil && *result.FailBlock != *tc.failBlock) {
+			t.Errorf("%s: Expected the failing block to be %v, got %v", tc.name, tc.failBlock, result.FailBlock)
+		}
+	}
+
+	// Blocks decoded before the failure are kept
+	result := NewItemDecoder().DecodeStringLenient(encoding.EncodeString([]byte{0, 1, 1, 0, 2, 'A', 0, 4, 1}))
+	if name, ok := result.Blocks[len(result.Blocks)-1].(*NameData); !ok || name.Name != "A" {
+		t.Errorf("Expected the name to be decoded before the failure, got %+v", result.Blocks)
+	}
+}
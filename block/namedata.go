@@ -47,17 +47,27 @@ func (n *NameData) Encode(ver types.EncodingVersion, out *[]byte) error {
 
 // DecodeData decodes data for this block from the given bytes
 func (n *NameData) DecodeData(bytes []byte, ver types.EncodingVersion) (int, error) {
-	// Find the null terminator
+	// Find the null terminator, the name has to be ASCII like it is when encoding
 	nullPos := -1
 	for i, b := range bytes {
 		if b == 0 {
 			nullPos = i
 			break
 		}
+		if b > 127 {
+			return i, &encoding.DecodeError{
+				Type: encoding.ErrNonAsciiString,
+				Err: &encoding.ValueError{
+					Name:   "name byte",
+					Value:  int64(b),
+					Reason: "is not ASCII",
+				},
+			}
+		}
 	}
 
 	if nullPos == -1 {
-		return 0, &encoding.DecodeError{Type: encoding.ErrUnexpectedEndOfBytes}
+		return len(bytes), &encoding.DecodeError{Type: encoding.ErrUnexpectedEndOfBytes}
 	}
 
 	// Extract the name (without the null terminator)
@@ -0,0 +1,138 @@
+package block
+
+import (
+	"errors"
+
+	"github.com/AevtJJ/idmangler/encoding"
+)
+
+// DecodeOptions holds the resource limits applied while decoding an ID string.
+// A limit of zero or less disables the check.
+type DecodeOptions struct {
+	// MaxInputLength is the maximum length of the ID string in bytes
+	MaxInputLength int
+	// MaxBlocks is the maximum number of blocks following the StartData block
+	MaxBlocks int
+	// MaxIdentifications is the maximum number of identifications in a single identification block
+	MaxIdentifications int
+	// MaxVarIntLength is the maximum number of bytes in a single varint
+	MaxVarIntLength int
+	// MaxAllocation is the maximum number of bytes allocated for the decoded data of an ID string
+	MaxAllocation int
+	// LenientVarInts accepts overlong and overflowing varints instead of rejecting them
+	LenientVarInts bool
+}
+
+// DefaultDecodeOptions returns limits which are safe for untrusted input
+// while leaving plenty of room for every item the game can produce
+func DefaultDecodeOptions() DecodeOptions {
+	return DecodeOptions{
+		MaxInputLength:     4096,
+		MaxBlocks:          32,
+		MaxIdentifications: 64,
+		MaxVarIntLength:    10,
+		MaxAllocation:      2048,
+	}
+}
+
+// limitError creates a decode error for an exceeded limit
+func limitError(limit string, value, max int) *encoding.DecodeError {
+	return &encoding.DecodeError{
+		Type: encoding.ErrLimitExceeded,
+		Err: &encoding.LimitError{
+			Limit: limit,
+			Value: value,
+			Max:   max,
+		},
+	}
+}
+
+// limitDecoderError wraps a limit error found outside of a block with the offset at which it was found
+func limitDecoderError(err error, offset int) error {
+	var decodeErr *encoding.DecodeError
+	if !errors.As(err, &decodeErr) {
+		decodeErr = &encoding.DecodeError{Type: encoding.ErrLimitExceeded, Err: err}
+	}
+	return &encoding.DecoderError{
+		ErrorData: decodeErr,
+		Offset:    offset,
+	}
+}
+
+// exceeds returns whether the value is above the given limit, limits of zero or less are disabled
+func exceeds(value, max int) bool {
+	return max > 0 && value > max
+}
+
+// checkInput checks the length of an ID string and the size of its decoded data against the limits
+func (o *DecodeOptions) checkInput(idString string) error {
+	if o == nil {
+		return nil
+	}
+
+	if exceeds(len(idString), o.MaxInputLength) {
+		return limitError("input length", len(idString), o.MaxInputLength)
+	}
+
+	// Every codepoint decodes to at most two bytes
+	if size := encoding.DecodedLength(idString); exceeds(size, o.MaxAllocation) {
+		return limitError("allocation", size, o.MaxAllocation)
+	}
+
+	return nil
+}
+
+// checkBlocks checks the number of decoded blocks against the limits
+func (o *DecodeOptions) checkBlocks(count int) error {
+	if o != nil && exceeds(count, o.MaxBlocks) {
+		return limitError("block count", count, o.MaxBlocks)
+	}
+	return nil
+}
+
+// checkIdentifications checks the number of identifications in a block against the limits
+func (o *DecodeOptions) checkIdentifications(count int) error {
+	if o != nil && exceeds(count, o.MaxIdentifications) {
+		return limitError("identification count", count, o.MaxIdentifications)
+	}
+	return nil
+}
+
+// decodeVarInt decodes a varint from the given bytes, enforcing the varint length limit.
+// Like encoding.DecodeVarIntMode it returns the number of bytes read even if decoding failed.
+func (o *DecodeOptions) decodeVarInt(bytes []byte) (int64, int, error) {
+	bytes, lenient := o.varIntInput(bytes)
+	value, n, err := encoding.DecodeVarIntMode(bytes, lenient)
+	return value, n, o.checkVarInt(n, err)
+}
+
+// decodeVarInt32 decodes a varint which has to fit into 32 bits from the given bytes,
+// enforcing the varint length limit. Like encoding.DecodeVarInt32 it returns the number of bytes read
+// even if decoding failed.
+func (o *DecodeOptions) decodeVarInt32(bytes []byte) (int32, int, error) {
+	bytes, lenient := o.varIntInput(bytes)
+	value, n, err := encoding.DecodeVarInt32(bytes, lenient)
+	return value, n, o.checkVarInt(n, err)
+}
+
+// varIntInput returns the bytes a varint may be read from and whether it is read in lenient mode
+func (o *DecodeOptions) varIntInput(bytes []byte) ([]byte, bool) {
+	if o == nil {
+		return bytes, false
+	}
+
+	// Never look further than one byte past the limit
+	if o.MaxVarIntLength > 0 && len(bytes) > o.MaxVarIntLength+1 {
+		bytes = bytes[:o.MaxVarIntLength+1]
+	}
+	return bytes, o.LenientVarInts
+}
+
+// checkVarInt checks the length of a decoded varint against the limits, a varint which is too long
+// is reported as such instead of the error decoding it
+func (o *DecodeOptions) checkVarInt(n int, err error) error {
+	if o != nil && exceeds(n, o.MaxVarIntLength) {
+		return limitError("varint length", n, o.MaxVarIntLength)
+	}
+	return err
+}
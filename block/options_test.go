@@ -0,0 +1,109 @@
+package block
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+func TestDecodeOptions(t *testing.T) {
+	rerolls := []byte{0, 1, 1, 0, 5, 1, 5, 1, 5, 1, 255}
+	idents := []byte{0, 1, 1, 0, 3, 3, 0, 1, 10, 2, 20, 3, 30, 255}
+	shiny := []byte{0, 1, 1, 0, 6, 1, 0x80, 0x80, 0x01, 255}
+
+	testCases := []struct {
+		name    string
+		input   string
+		options DecodeOptions
+		limit   string
+		offset  int
+	}{
+		{"input length", encoding.EncodeString(rerolls), DecodeOptions{MaxInputLength: 8}, "input length", -1},
+		{"allocation", encoding.EncodeString(rerolls), DecodeOptions{MaxAllocation: 4}, "allocation", -1},
+		{"blocks", encoding.EncodeString(rerolls), DecodeOptions{MaxBlocks: 3}, "block count", 8},
+		{"identifications", encoding.EncodeString(idents), DecodeOptions{MaxIdentifications: 2}, "identification count", 5},
+		{"varint length", encoding.EncodeString(shiny), DecodeOptions{MaxVarIntLength: 2}, "varint length", 6},
+		{"no limits", encoding.EncodeString(shiny), DecodeOptions{}, "", 0},
+		{"defaults", encoding.EncodeString(idents), DefaultDecodeOptions(), "", 0},
+		{"default input length", strings.Repeat(encoding.EncodeString([]byte{5, 1}), 2000), DefaultDecodeOptions(), "input length", -1},
+	}
+
+	for _, tc := range testCases {
+		_, err := NewItemDecoder().WithSchemaValidation(false).WithOptions(tc.options).DecodeString(tc.input)
+
+		if tc.limit == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tc.name, err)
+			}
+			continue
+		}
+
+		var limitErr *encoding.LimitError
+		if !errors.Is(err, encoding.ErrLimitExceeded) || !errors.As(err, &limitErr) {
+			t.Errorf("%s: expected a limit error, got %v", tc.name, err)
+			continue
+		}
+		if limitErr.Limit != tc.limit {
+			t.Errorf("%s: expected limit %q, got %q", tc.name, tc.limit, limitErr.Limit)
+		}
+
+		var decoderErr *encoding.DecoderError
+		if !errors.As(err, &decoderErr) || decoderErr.Offset != tc.offset {
+			t.Errorf("%s: expected offset %d, got %v", tc.name, tc.offset, err)
+		}
+	}
+}
+
+func TestDecodeAllBlocksOptions(t *testing.T) {
+	// 40 reroll blocks are more than DefaultDecodeOptions allows
+	var bytes []byte
+	for i := 0; i < 40; i++ {
+		bytes = append(bytes, byte(BlockRerollData), 1)
+	}
+	bytes = append(bytes, byte(BlockEndData))
+
+	blocks, err := DecodeAllBlocks(types.Version1, bytes)
+	if err != nil || len(blocks) != 41 {
+		t.Errorf("Expected 41 blocks without limits, got %d: %v", len(blocks), err)
+	}
+
+	_, err = DecodeAllBlocksWithOptions(types.Version1, bytes, DefaultDecodeOptions())
+	if !errors.Is(err, encoding.ErrLimitExceeded) {
+		t.Errorf("Expected %v, got %v", encoding.ErrLimitExceeded, err)
+	}
+}
+
+func TestDecodeVarInt32MatchesEncoding(t *testing.T) {
+	testCases := []struct {
+		name    string
+		bytes   []byte
+		lenient bool
+	}{
+		{"valid", []byte{0x80, 0x01}, false},
+		{"truncated", []byte{0x80, 0x80}, false},
+		{"overlong", []byte{0x80, 0x00}, false},
+		{"overlong lenient", []byte{0x80, 0x00}, true},
+		{"out of range", encoding.EncodeVarInt(1 << 40), false},
+	}
+
+	for _, tc := range testCases {
+		expectedValue, expectedN, expectedErr := encoding.DecodeVarInt32(tc.bytes, tc.lenient)
+		value, n, err := (&DecodeOptions{LenientVarInts: tc.lenient}).decodeVarInt32(tc.bytes)
+
+		if value != expectedValue || n != expectedN || !errors.Is(err, encoding.ErrInvalidVarInt) != (expectedErr == nil) {
+			t.Errorf("%s: Expected %d, %d, %v, got %d, %d, %v", tc.name, expectedValue, expectedN, expectedErr, value, n, err)
+		}
+	}
+}
+
+func TestIdentificationCountBeyondData(t *testing.T) {
+	bytes := []byte{byte(BlockIdentificationData), 200, 0, 1, 10}
+
+	_, _, err := DecodeBlock(types.Version1, bytes)
+	if !errors.Is(err, encoding.ErrUnexpectedEndOfBytes) {
+		t.Errorf("Expected %v, got %v", encoding.ErrUnexpectedEndOfBytes, err)
+	}
+}
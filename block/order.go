@@ -0,0 +1,129 @@
+package block
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/AevtJJ/idmangler/types"
+)
+
+// OrderError represents a block that is out of canonical order or duplicated
+type OrderError struct {
+	// Block is the block that is out of order or duplicated
+	Block DataBlockID
+	// Previous is the block preceding the offending block
+	Previous DataBlockID
+	// Index is the position of the offending block
+	Index int
+}
+
+// Error returns the error message for an out of order block
+func (e *OrderError) Error() string {
+	if e.Block == e.Previous {
+		return fmt.Sprintf("duplicate %s at index %d", e.Block, e.Index)
+	}
+	return fmt.Sprintf("%s at index %d must come before %s", e.Block, e.Index, e.Previous)
+}
+
+// SortBlocks returns a copy of the given blocks sorted into the canonical order used by Wynntils.
+// StartData always comes first and EndData last, the other blocks are ordered according to the
+// schema of the item type. Blocks which are not part of the schema are placed after the others
+// in block ID order. The relative order of equal blocks is preserved.
+func SortBlocks(blocks []AnyBlock) []AnyBlock {
+	schema := schemaForBlocks(blocks)
+
+	sorted := make([]AnyBlock, len(blocks))
+	copy(sorted, blocks)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return blockRank(schema, sorted[i]) < blockRank(schema, sorted[j])
+	})
+
+	return sorted
+}
+
+// ValidateOrder checks that the given blocks are in canonical order
+// and that only repeatable blocks appear more than once
+func ValidateOrder(blocks []AnyBlock) error {
+	schema := schemaForBlocks(blocks)
+
+	for i := 1; i < len(blocks); i++ {
+		prev := blocks[i-1].AsID()
+		id := blocks[i].AsID()
+
+		if id == prev && !repeatable(schema, id) {
+			return &OrderError{Block: id, Previous: prev, Index: i}
+		}
+
+		if blockRank(schema, blocks[i]) < blockRank(schema, blocks[i-1]) {
+			return &OrderError{Block: id, Previous: prev, Index: i}
+		}
+	}
+
+	return nil
+}
+
+// blockRank returns the position of a block within the canonical order.
+// A raw tail contains the original end of the data and always comes last.
+func blockRank(schema *ItemSchema, b AnyBlock) int {
+	if _, ok := b.(*RawTailBlock); ok {
+		return 1 << 17
+	}
+	return canonicalRank(schema, b.AsID())
+}
+
+// canonicalRank returns the position of a block ID within the canonical order
+func canonicalRank(schema *ItemSchema, id DataBlockID) int {
+	switch id {
+	case BlockStartData:
+		return -1
+	case BlockEndData:
+		return 1 << 16
+	}
+
+	if schema != nil {
+		for i, rule := range schema.Blocks {
+			if rule.ID == id {
+				return i
+			}
+		}
+	}
+
+	// Blocks without a schema position go after all the others
+	return 256 + int(id)
+}
+
+// repeatable returns whether a block may appear more than once.
+// Without a schema only ShinyData is repeatable.
+func repeatable(schema *ItemSchema, id DataBlockID) bool {
+	if schema != nil {
+		if rule, ok := schema.Rule(id); ok {
+			return rule.Repeatable
+		}
+	}
+	return id == BlockShinyData
+}
+
+// itemTypeOf returns the item type stored in the first TypeData block.
+// Returns false if there is no TypeData block.
+func itemTypeOf(blocks []AnyBlock) (types.ItemType, bool) {
+	for _, b := range blocks {
+		if t, ok := b.(*TypeData); ok {
+			return t.ItemType, true
+		}
+	}
+	return 0, false
+}
+
+// schemaForBlocks returns the schema of the item type of the given blocks, nil if it is not known
+func schemaForBlocks(blocks []AnyBlock) *ItemSchema {
+	itemType, ok := itemTypeOf(blocks)
+	if !ok {
+		return nil
+	}
+
+	schema, ok := SchemaFor(itemType)
+	if !ok {
+		return nil
+	}
+	return &schema
+}
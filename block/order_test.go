@@ -0,0 +1,66 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/AevtJJ/idmangler/types"
+)
+
+func TestSortBlocksProducesIdenticalStrings(t *testing.T) {
+	canonical := []AnyBlock{
+		NewStartData(types.Version1),
+		NewTypeData(types.Gear),
+		NewNameData("Aftershock"),
+		NewPowderData(4, nil),
+		NewRerollData(2),
+		NewShinyData(1, 10),
+		NewEndData(),
+	}
+	shuffled := []AnyBlock{
+		NewRerollData(2),
+		NewShinyData(1, 10),
+		NewPowderData(4, nil),
+		NewNameData("Aftershock"),
+		NewTypeData(types.Gear),
+	}
+
+	encoder := NewItemEncoder().WithSorting(true).WithOrderValidation(true)
+
+	expected, err := encoder.EncodeBlocks(canonical)
+	if err != nil {
+		t.Fatalf("Error encoding canonical blocks: %v", err)
+	}
+
+	actual, err := encoder.EncodeBlocks(shuffled)
+	if err != nil {
+		t.Fatalf("Error encoding shuffled blocks: %v", err)
+	}
+
+	if expected != actual {
+		t.Errorf("Encoded strings differ after sorting. Expected %q, got %q", expected, actual)
+	}
+}
+
+func TestValidateOrder(t *testing.T) {
+	testCases := []struct {
+		blocks []AnyBlock
+		valid  bool
+	}{
+		{[]AnyBlock{NewStartData(types.Version1), NewTypeData(types.Gear), NewNameData("A"), NewEndData()}, true},
+		{[]AnyBlock{NewTypeData(types.Gear), NewShinyData(1, 1), NewShinyData(2, 2)}, true},
+		{[]AnyBlock{NewTypeData(types.Gear), NewNameData("A"), NewNameData("B")}, false},
+		{[]AnyBlock{NewTypeData(types.Gear), NewPowderData(1, nil), NewNameData("A")}, false},
+		{[]AnyBlock{NewTypeData(types.CraftedGear), NewPowderData(1, nil), NewNameData("A")}, true},
+		{[]AnyBlock{NewNameData("A"), NewStartData(types.Version1)}, false},
+	}
+
+	for _, tc := range testCases {
+		err := ValidateOrder(tc.blocks)
+		if tc.valid && err != nil {
+			t.Errorf("Unexpected order error for %+v: %v", tc.blocks, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("Expected an order error for %+v", tc.blocks)
+		}
+	}
+}
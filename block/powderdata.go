@@ -35,8 +35,15 @@ func (p *PowderData) EncodeData(ver types.EncodingVersion, out *[]byte) error {
 	bitsNeeded := len(p.Powders) * 5
 	totalBytes := (bitsNeeded + 7) / 8
 
-	// Create the powder data bytes
-	powderData := make([]byte, totalBytes)
+	// Write the powder slots
+	*out = append(*out, p.PowderSlots)
+	// Write the number of powders
+	*out = append(*out, byte(len(p.Powders)))
+
+	// Reserve the zeroed powder data bytes at the end of the output
+	start := len(*out)
+	*out = append(*out, make([]byte, totalBytes)...)
+	powderData := (*out)[start:]
 
 	// Encode each powder
 	for i, powder := range p.Powders {
@@ -57,13 +64,6 @@ func (p *PowderData) EncodeData(ver types.EncodingVersion, out *[]byte) error {
 		}
 	}
 
-	// Write the powder slots
-	*out = append(*out, p.PowderSlots)
-	// Write the number of powders
-	*out = append(*out, byte(len(p.Powders)))
-	// Write the powder data
-	*out = append(*out, powderData...)
-
 	return nil
 }
 
@@ -93,56 +93,41 @@ func (p *PowderData) DecodeData(bytes []byte, ver types.EncodingVersion) (int, e
 	totalBytes := (bitsNeeded + 7) / 8
 
 	if len(bytes) < bytesUsed+totalBytes {
-		return 0, &encoding.DecodeError{Type: encoding.ErrUnexpectedEndOfBytes}
+		return bytesUsed, &encoding.DecodeError{Type: encoding.ErrUnexpectedEndOfBytes}
 	}
 
 	// Read the powder data bytes
 	powderDataBytes := bytes[bytesUsed : bytesUsed+totalBytes]
 	bytesUsed += totalBytes
 
+	// The bits after the last powder are always encoded as zero
+	if padding := totalBytes*8 - bitsNeeded; padding > 0 && powderDataBytes[totalBytes-1]&(1<<padding-1) != 0 {
+		return bytesUsed - 1, &encoding.DecodeError{
+			Type: encoding.ErrNonCanonical,
+			Err:  powderPaddingError(powderDataBytes[totalBytes-1] & (1<<padding - 1)),
+		}
+	}
+
 	// Initialize the powders slice
 	p.Powders = make([]types.Powder, 0, powderCount)
 
 	// Decode each powder
 	for powderIdx := 0; powderIdx < powderCount; powderIdx++ {
-		var powderValue byte
+		powderValue := powderCell(powderDataBytes, powderIdx)
 
-		// Extract the 5 bits for this powder
-		for i := 0; i < 5; i++ {
-			idx := (powderIdx * 5) + i
-			bit := (powderDataBytes[idx/8] >> (7 - (idx % 8))) & 0b1
-			powderValue |= bit << (4 - i)
-		}
-
-		// Skip empty powders
+		// Empty cells are never encoded, the count would change when encoding the powders again
 		if powderValue == 0 {
-			continue
-		}
-
-		// Calculate element and tier from the powder value
-		var elem, tier byte
-		if powderValue%6 == 0 {
-			elem = (powderValue / 6) - 1
-			tier = 6
-		} else {
-			elem = powderValue / 6
-			tier = powderValue % 6
-		}
-
-		// Create the powder
-		element, err := types.ElementFromByte(elem)
-		if err != nil {
-			return 0, &encoding.DecodeError{
-				Type:    encoding.ErrBadElement,
-				Details: elem,
+			return 2 + (powderIdx*5)/8, &encoding.DecodeError{
+				Type: encoding.ErrNonCanonical,
+				Err:  emptyPowderError(powderIdx),
 			}
 		}
 
-		powder, err := types.NewPowder(element, tier)
+		powder, kind, err := powderFromCell(powderValue)
 		if err != nil {
-			return 0, &encoding.DecodeError{
-				Type:    encoding.ErrBadPowderTier,
-				Details: tier,
+			return 2 + (powderIdx*5)/8, &encoding.DecodeError{
+				Type: kind,
+				Err:  err,
 			}
 		}
 
@@ -152,6 +137,61 @@ func (p *PowderData) DecodeData(bytes []byte, ver types.EncodingVersion) (int, e
 	return bytesUsed, nil
 }
 
+// emptyPowderError describes an empty powder cell
+func emptyPowderError(index int) error {
+	return &encoding.ValueError{
+		Name:   "powder at index",
+		Value:  int64(index),
+		Reason: "is empty",
+	}
+}
+
+// powderPaddingError describes padding bits after the last powder which are not zero
+func powderPaddingError(bits byte) error {
+	return &encoding.ValueError{
+		Name:   "powder padding",
+		Value:  int64(bits),
+		Reason: "is not zero",
+	}
+}
+
+// powderCell extracts the 5 bit value of the powder at the given index from the powder data bytes
+func powderCell(data []byte, powderIdx int) byte {
+	var powderValue byte
+	for i := 0; i < 5; i++ {
+		idx := (powderIdx * 5) + i
+		bit := (data[idx/8] >> (7 - (idx % 8))) & 0b1
+		powderValue |= bit << (4 - i)
+	}
+	return powderValue
+}
+
+// powderFromCell converts a non-empty 5 bit powder value to a powder.
+// On failure the error kind describing the problem is returned as well.
+func powderFromCell(powderValue byte) (types.Powder, encoding.ErrorKind, error) {
+	// Calculate element and tier from the powder value
+	var elem, tier byte
+	if powderValue%6 == 0 {
+		elem = (powderValue / 6) - 1
+		tier = 6
+	} else {
+		elem = powderValue / 6
+		tier = powderValue % 6
+	}
+
+	element, err := types.ElementFromByte(elem)
+	if err != nil {
+		return types.Powder{}, encoding.ErrBadElement, err
+	}
+
+	powder, err := types.NewPowder(element, tier)
+	if err != nil {
+		return types.Powder{}, encoding.ErrBadPowderTier, err
+	}
+
+	return powder, 0, nil
+}
+
 // NewPowderData creates a new PowderData block with the specified slots and powders
 func NewPowderData(powderSlots byte, powders []types.Powder) *PowderData {
 	return &PowderData{
@@ -0,0 +1,70 @@
+package block
+
+import (
+	"github.com/AevtJJ/idmangler/types"
+)
+
+// RawTailBlock holds an unknown block and everything after it verbatim.
+// Since the layout of an unknown block is not known, neither is where it ends, so the
+// remaining data including any EndData is kept as is and written back byte for byte.
+// This allows strings produced by newer versions of Wynntils to be round tripped.
+type RawTailBlock struct {
+	// ID is the unknown block ID
+	ID byte
+	// Data contains all bytes following the block ID
+	Data []byte
+}
+
+// BlockID returns the ID of this block
+func (r *RawTailBlock) BlockID() DataBlockID {
+	return DataBlockID(r.ID)
+}
+
+// AsID returns the ID of this block
+func (r *RawTailBlock) AsID() DataBlockID {
+	return r.BlockID()
+}
+
+// EncodeData encodes this block's data into the given output buffer
+func (r *RawTailBlock) EncodeData(ver types.EncodingVersion, out *[]byte) error {
+	*out = append(*out, r.Data...)
+	return nil
+}
+
+// Encode encodes this block with its ID into the given output buffer
+func (r *RawTailBlock) Encode(ver types.EncodingVersion, out *[]byte) error {
+	// Write block ID
+	*out = append(*out, r.ID)
+	// Write block data
+	return r.EncodeData(ver, out)
+}
+
+// DecodeData decodes data for this block from the given bytes, consuming all of them
+func (r *RawTailBlock) DecodeData(bytes []byte, ver types.EncodingVersion) (int, error) {
+	r.Data = make([]byte, len(bytes))
+	copy(r.Data, bytes)
+	return len(bytes), nil
+}
+
+// NewRawTailBlock creates a new RawTailBlock with the specified block ID and remaining data
+func NewRawTailBlock(id byte, data []byte) *RawTailBlock {
+	return &RawTailBlock{
+		ID:   id,
+		Data: data,
+	}
+}
+
+// decodeRawTail captures an unknown block and all remaining bytes.
+// Returns false if the bytes start with a known block ID.
+func decodeRawTail(ver types.EncodingVersion, bytes []byte) (*RawTailBlock, int, bool) {
+	if len(bytes) == 0 {
+		return nil, 0, false
+	}
+	if _, err := DataBlockIDFromByte(bytes[0]); err == nil {
+		return nil, 0, false
+	}
+
+	tail := &RawTailBlock{ID: bytes[0]}
+	n, _ := tail.DecodeData(bytes[1:], ver)
+	return tail, 1 + n, true
+}
@@ -0,0 +1,43 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/AevtJJ/idmangler/encoding"
+)
+
+func TestRawTailBlockRoundTrip(t *testing.T) {
+	testCases := [][]byte{
+		{0, 1, 1, 0, 2, 'A', 0, 200, 1, 2, 3, 255},
+		{0, 1, 1, 0, 2, 'A', 0, 5, 2, 16, 255, 0, 7},
+		{0, 1, 1, 0, 2, 'A', 0, 99},
+	}
+
+	for _, tc := range testCases {
+		idString := encoding.EncodeString(tc)
+
+		if _, err := NewItemDecoder().DecodeString(idString); err == nil {
+			t.Errorf("Expected an error decoding %v without preserving unknown blocks", tc)
+		}
+
+		blocks, err := NewItemDecoder().WithUnknownBlocks(true).DecodeString(idString)
+		if err != nil {
+			t.Errorf("Error decoding %v: %v", tc, err)
+			continue
+		}
+
+		if _, ok := blocks[len(blocks)-1].(*RawTailBlock); !ok {
+			t.Errorf("Expected the last block of %v to be a RawTailBlock, got %+v", tc, blocks)
+		}
+
+		encoded, err := NewItemEncoder().EncodeBlocks(blocks)
+		if err != nil {
+			t.Errorf("Error encoding %v: %v", tc, err)
+			continue
+		}
+
+		if encoded != idString {
+			t.Errorf("String mismatch after round trip of %v", tc)
+		}
+	}
+}
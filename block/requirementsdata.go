@@ -0,0 +1,179 @@
+package block
+
+import (
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+// RequirementsData represents the requirements block of an encoded item string.
+// It stores the level, class and skill point requirements needed to use the item.
+type RequirementsData struct {
+	// Level is the combat level required to use the item
+	Level byte
+	// Class is the class the item is restricted to, nil if any class can use it
+	Class *types.ClassType
+	// Skills is a list of skill point requirements, at most one per skill
+	Skills []types.SkillRequirement
+}
+
+// BlockID returns the ID of this block
+func (r *RequirementsData) BlockID() DataBlockID {
+	return BlockRequirementsData
+}
+
+// AsID returns the ID of this block
+func (r *RequirementsData) AsID() DataBlockID {
+	return r.BlockID()
+}
+
+// EncodeData encodes this block's data into the given output buffer
+func (r *RequirementsData) EncodeData(ver types.EncodingVersion, out *[]byte) error {
+	var seen skillSet
+	for _, skill := range r.Skills {
+		if kind, err := seen.add(skill); err != nil {
+			return &encoding.EncodeError{
+				Type: kind,
+				Err:  err,
+			}
+		}
+	}
+
+	// Write the level requirement
+	*out = append(*out, r.Level)
+
+	// Write the class requirement, 0 means no class requirement
+	if r.Class != nil {
+		if _, err := types.ClassTypeFromByte(byte(*r.Class)); err != nil {
+			return &encoding.EncodeError{
+				Type: encoding.ErrBadClassType,
+				Err:  err,
+			}
+		}
+		*out = append(*out, byte(*r.Class))
+	} else {
+		*out = append(*out, 0)
+	}
+
+	// Write the skill requirements
+	*out = append(*out, byte(len(r.Skills)))
+	for _, skill := range r.Skills {
+		*out = append(*out, byte(skill.Skill))
+		*out = encoding.AppendVarInt(*out, int64(skill.Points))
+	}
+
+	return nil
+}
+
+// Encode encodes this block with its ID into the given output buffer
+func (r *RequirementsData) Encode(ver types.EncodingVersion, out *[]byte) error {
+	// Write block ID
+	*out = append(*out, byte(r.BlockID()))
+	// Write block data
+	return r.EncodeData(ver, out)
+}
+
+// DecodeData decodes data for this block from the given bytes
+func (r *RequirementsData) DecodeData(bytes []byte, ver types.EncodingVersion) (int, error) {
+	return r.decodeDataWith(bytes, ver, nil)
+}
+
+// decodeDataWith decodes data for this block from the given bytes within the given limits
+func (r *RequirementsData) decodeDataWith(bytes []byte, ver types.EncodingVersion, opts *DecodeOptions) (int, error) {
+	if len(bytes) < 3 {
+		return 0, &encoding.DecodeError{Type: encoding.ErrUnexpectedEndOfBytes}
+	}
+
+	// Read the level requirement
+	level := bytes[0]
+
+	// Read the class requirement
+	var class *types.ClassType
+	if bytes[1] != 0 {
+		classType, err := types.ClassTypeFromByte(bytes[1])
+		if err != nil {
+			return 1, &encoding.DecodeError{
+				Type: encoding.ErrBadClassType,
+				Err:  err,
+			}
+		}
+		class = &classType
+	}
+
+	// Read the skill requirements
+	skillCount := int(bytes[2])
+	bytesUsed := 3
+
+	var seen skillSet
+	skills := make([]types.SkillRequirement, 0)
+	for i := 0; i < skillCount; i++ {
+		entryStart := bytesUsed
+		if len(bytes) <= bytesUsed {
+			return bytesUsed, &encoding.DecodeError{Type: encoding.ErrUnexpectedEndOfBytes}
+		}
+
+		skill, err := types.SkillTypeFromByte(bytes[bytesUsed])
+		if err != nil {
+			return bytesUsed, &encoding.DecodeError{
+				Type: encoding.ErrBadSkillType,
+				Err:  err,
+			}
+		}
+		bytesUsed++
+
+		points, n, err := opts.decodeVarInt32(bytes[bytesUsed:])
+		if err != nil {
+			return bytesUsed, err
+		}
+		bytesUsed += n
+
+		requirement := types.SkillRequirement{Skill: skill, Points: points}
+		if kind, err := seen.add(requirement); err != nil {
+			return entryStart, &encoding.DecodeError{
+				Type: kind,
+				Err:  err,
+			}
+		}
+
+		skills = append(skills, requirement)
+	}
+
+	r.Level = level
+	r.Class = class
+	r.Skills = skills
+
+	return bytesUsed, nil
+}
+
+// skillSet tracks the skills which already have a requirement
+type skillSet [byte(types.Agility) + 1]bool
+
+// add checks that a skill requirement is for a valid skill which has no requirement yet
+// and does not require a negative amount of skill points, then marks the skill as seen.
+// Returns the kind of error and the error if the requirement is invalid.
+func (s *skillSet) add(skill types.SkillRequirement) (encoding.ErrorKind, error) {
+	if _, err := types.SkillTypeFromByte(byte(skill.Skill)); err != nil {
+		return encoding.ErrBadSkillType, err
+	}
+	if s[skill.Skill] {
+		return encoding.ErrInvalidSkillRequirement, duplicateError(skill.Skill.String()+" skill", int64(skill.Skill))
+	}
+	if skill.Points < 0 {
+		return encoding.ErrInvalidSkillRequirement, &encoding.ValueError{
+			Name:   skill.Skill.String() + " requirement",
+			Value:  int64(skill.Points),
+			Reason: "is negative",
+		}
+	}
+
+	s[skill.Skill] = true
+	return 0, nil
+}
+
+// NewRequirementsData creates a new RequirementsData block with the specified requirements
+func NewRequirementsData(level byte, class *types.ClassType, skills []types.SkillRequirement) *RequirementsData {
+	return &RequirementsData{
+		Level:  level,
+		Class:  class,
+		Skills: skills,
+	}
+}
@@ -0,0 +1,89 @@
+package block
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+func TestRequirementsDataRoundTrip(t *testing.T) {
+	mage := types.Mage
+	shaman := types.Shaman
+	id := byte(BlockRequirementsData)
+
+	testCases := []struct {
+		block *RequirementsData
+		bytes []byte
+	}{
+		{NewRequirementsData(1, nil, []types.SkillRequirement{}), []byte{id, 1, 0, 0}},
+		{NewRequirementsData(60, &mage, []types.SkillRequirement{}), []byte{id, 60, 1, 0}},
+		{NewRequirementsData(105, &shaman, []types.SkillRequirement{
+			{Skill: types.Agility, Points: 30},
+			{Skill: types.Strength, Points: 0},
+		}), []byte{id, 105, 5, 2, 4, 60, 0, 0}},
+		{NewRequirementsData(80, nil, []types.SkillRequirement{
+			{Skill: types.Strength, Points: 1},
+			{Skill: types.Dexterity, Points: 2},
+			{Skill: types.Intelligence, Points: 3},
+			{Skill: types.Defense, Points: 4},
+			{Skill: types.Agility, Points: 100},
+		}), []byte{id, 80, 0, 5, 0, 2, 1, 4, 2, 6, 3, 8, 4, 0xC8, 0x01}},
+	}
+
+	for _, tc := range testCases {
+		encoded := roundTripBlock(t, tc.block)
+		if !bytes.Equal(encoded, tc.bytes) {
+			t.Errorf("Incorrect encoding of %+v. Expected %v, got %v", tc.block, tc.bytes, encoded)
+		}
+	}
+}
+
+func TestRequirementsDataInvalid(t *testing.T) {
+	id := byte(BlockRequirementsData)
+	badClass := types.ClassType(6)
+
+	testCases := []struct {
+		name   string
+		block  *RequirementsData
+		bytes  []byte
+		kind   encoding.ErrorKind
+		offset int
+	}{
+		{"bad class", NewRequirementsData(1, &badClass, nil), []byte{id, 1, 6, 0}, encoding.ErrBadClassType, 2},
+		{"bad skill", NewRequirementsData(1, nil, []types.SkillRequirement{{Skill: 5, Points: 1}}), []byte{id, 1, 0, 1, 5, 2}, encoding.ErrBadSkillType, 4},
+		{"duplicate skill", NewRequirementsData(1, nil, []types.SkillRequirement{
+			{Skill: types.Defense, Points: 1},
+			{Skill: types.Defense, Points: 2},
+		}), []byte{id, 1, 0, 2, 3, 2, 3, 4}, encoding.ErrInvalidSkillRequirement, 6},
+		{"negative points", NewRequirementsData(1, nil, []types.SkillRequirement{{Skill: types.Agility, Points: -1}}), []byte{id, 1, 0, 1, 4, 1}, encoding.ErrInvalidSkillRequirement, 4},
+	}
+
+	for _, tc := range testCases {
+		var out []byte
+		if err := tc.block.Encode(types.Version1, &out); !errors.Is(err, tc.kind) {
+			t.Errorf("%s: Expected %v when encoding, got %v", tc.name, tc.kind, err)
+		}
+
+		_, _, err := DecodeBlock(types.Version1, tc.bytes)
+		var decoderErr *encoding.DecoderError
+		if !errors.Is(err, tc.kind) || !errors.As(err, &decoderErr) || decoderErr.Offset != tc.offset {
+			t.Errorf("%s: Expected %v at offset %d when decoding, got %v", tc.name, tc.kind, tc.offset, err)
+		}
+	}
+}
+
+func TestClassTypeFromByte(t *testing.T) {
+	for b := 0; b < 256; b++ {
+		class, err := types.ClassTypeFromByte(byte(b))
+		valid := b >= 1 && b <= 5
+		if valid && (err != nil || byte(class) != byte(b)) {
+			t.Errorf("Expected class %d to be valid, got %v", b, err)
+		}
+		if !valid && err == nil {
+			t.Errorf("Expected class %d to be rejected", b)
+		}
+	}
+}
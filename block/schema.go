@@ -0,0 +1,223 @@
+package block
+
+import (
+	"fmt"
+
+	"github.com/AevtJJ/idmangler/types"
+)
+
+// BlockRule describes how a block may appear within an item of a specific type
+type BlockRule struct {
+	// ID is the ID of the block
+	ID DataBlockID
+	// Required is whether the block must be present
+	Required bool
+	// Repeatable is whether the block may appear more than once
+	Repeatable bool
+}
+
+// ItemSchema describes which blocks are legal for an item type.
+// Any block not listed in the schema is forbidden, except for StartData and EndData
+// which are legal for every item type.
+type ItemSchema struct {
+	// ItemType is the item type this schema applies to
+	ItemType types.ItemType
+	// Blocks contains the rules for every block allowed in the item
+	Blocks []BlockRule
+}
+
+// itemSchemas contains the schema for every item type
+var itemSchemas = map[types.ItemType]ItemSchema{
+	types.Gear: {
+		ItemType: types.Gear,
+		Blocks: []BlockRule{
+			{ID: BlockTypeData, Required: true},
+			{ID: BlockNameData, Required: true},
+			{ID: BlockIdentificationData},
+			{ID: BlockPowderData},
+			{ID: BlockRerollData},
+			{ID: BlockShinyData, Repeatable: true},
+		},
+	},
+	types.Tome: {
+		ItemType: types.Tome,
+		Blocks: []BlockRule{
+			{ID: BlockTypeData, Required: true},
+			{ID: BlockNameData, Required: true},
+			{ID: BlockIdentificationData},
+			{ID: BlockRerollData},
+		},
+	},
+	types.Charm: {
+		ItemType: types.Charm,
+		Blocks: []BlockRule{
+			{ID: BlockTypeData, Required: true},
+			{ID: BlockNameData, Required: true},
+			{ID: BlockIdentificationData},
+			{ID: BlockRerollData},
+		},
+	},
+	types.CraftedGear: {
+		ItemType: types.CraftedGear,
+		Blocks: []BlockRule{
+			{ID: BlockTypeData, Required: true},
+			{ID: BlockCraftedGearType, Required: true},
+			{ID: BlockDurabilityData, Required: true},
+			{ID: BlockRequirementsData, Required: true},
+			{ID: BlockDamageData},
+			{ID: BlockDefenseData},
+			{ID: BlockCraftedIdentificationData},
+			{ID: BlockPowderData},
+			{ID: BlockNameData},
+		},
+	},
+	types.CraftedConsu: {
+		ItemType: types.CraftedConsu,
+		Blocks: []BlockRule{
+			{ID: BlockTypeData, Required: true},
+			{ID: BlockCraftedConsumableTypeData, Required: true},
+			{ID: BlockUsesData, Required: true},
+			{ID: BlockEffectsData, Required: true},
+			{ID: BlockRequirementsData, Required: true},
+			{ID: BlockCraftedIdentificationData},
+			{ID: BlockNameData},
+		},
+	},
+}
+
+// SchemaFor returns the schema for the given item type.
+// Returns false if no schema is known for the item type.
+func SchemaFor(itemType types.ItemType) (ItemSchema, bool) {
+	schema, ok := itemSchemas[itemType]
+	return schema, ok
+}
+
+// Rule returns the rule for the given block ID.
+// Returns false if the block is forbidden in this schema.
+func (s ItemSchema) Rule(id DataBlockID) (BlockRule, bool) {
+	for _, rule := range s.Blocks {
+		if rule.ID == id {
+			return rule, true
+		}
+	}
+	return BlockRule{}, false
+}
+
+// SchemaError represents a violation of the schema of an item type
+type SchemaError struct {
+	// ItemType is the type of the item, nil if it could not be determined
+	ItemType *types.ItemType
+	// Block is the block that violates the schema
+	Block DataBlockID
+	// Reason describes how the schema was violated
+	Reason string
+}
+
+// Error returns the error message for a schema violation
+func (e *SchemaError) Error() string {
+	if e.ItemType == nil {
+		return fmt.Sprintf("%s: %s", e.Block, e.Reason)
+	}
+	return fmt.Sprintf("%s items %s %s", *e.ItemType, e.Reason, e.Block)
+}
+
+// typedSchemaError creates a SchemaError for an item of the given type.
+// Taking the address of a copy keeps the item type of a valid item from escaping to the heap.
+func typedSchemaError(itemType types.ItemType, block DataBlockID, reason string) *SchemaError {
+	return &SchemaError{ItemType: &itemType, Block: block, Reason: reason}
+}
+
+// ValidateSchema checks that the given blocks only contain the blocks allowed for their item type,
+// that every required block is present and that no block appears more often than allowed.
+// The item type is read from the TypeData block.
+func ValidateSchema(blocks []AnyBlock) error {
+	var checker schemaChecker
+	for _, b := range blocks {
+		if err := checker.check(b); err != nil {
+			return err
+		}
+	}
+	return checker.finish()
+}
+
+// schemaChecker checks blocks against the schema of their item type one at a time,
+// so items can be validated while they are decoded
+type schemaChecker struct {
+	// typed is set once the TypeData block was checked
+	typed    bool
+	itemType types.ItemType
+	schema   ItemSchema
+	// counts holds the number of times each block was seen
+	counts [256]int
+}
+
+// check checks the next block for being forbidden or repeated.
+// Blocks before the TypeData block are checked once the item type is known.
+func (c *schemaChecker) check(b AnyBlock) error {
+	id := b.AsID()
+	if id == BlockStartData || id == BlockEndData {
+		return nil
+	}
+
+	// Unknown blocks cannot be checked against the schema
+	if _, ok := b.(*RawTailBlock); ok {
+		return nil
+	}
+
+	c.counts[id]++
+	if c.typed {
+		return c.checkCount(id)
+	}
+
+	// Blocks before the TypeData block wait for the item type
+	if typeData, ok := b.(*TypeData); ok {
+		return c.setType(typeData.ItemType)
+	}
+	return nil
+}
+
+// setType looks up the schema of the item type and checks every block seen so far against it
+func (c *schemaChecker) setType(itemType types.ItemType) error {
+	c.typed = true
+	c.itemType = itemType
+	schema, ok := SchemaFor(itemType)
+	if !ok {
+		return &SchemaError{Block: BlockTypeData, Reason: fmt.Sprintf("no schema known for item type %s", itemType)}
+	}
+	c.schema = schema
+
+	for seen, count := range c.counts {
+		if count > 0 {
+			if err := c.checkCount(DataBlockID(seen)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkCount checks that a block is allowed and not seen more often than allowed
+func (c *schemaChecker) checkCount(id DataBlockID) error {
+	rule, ok := c.schema.Rule(id)
+	if !ok {
+		return typedSchemaError(c.itemType, id, "cannot contain")
+	}
+	if c.counts[id] > 1 && !rule.Repeatable {
+		return typedSchemaError(c.itemType, id, "cannot contain more than one")
+	}
+	return nil
+}
+
+// finish checks that the item type was found and that every required block was seen
+func (c *schemaChecker) finish() error {
+	if !c.typed {
+		return &SchemaError{Block: BlockTypeData, Reason: "no TypeData block found to determine the item type"}
+	}
+
+	for _, rule := range c.schema.Blocks {
+		if rule.Required && c.counts[rule.ID] == 0 {
+			return typedSchemaError(c.itemType, rule.ID, "require")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package block
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+func TestValidateSchema(t *testing.T) {
+	testCases := []struct {
+		blocks []AnyBlock
+		valid  bool
+	}{
+		{[]AnyBlock{NewTypeData(types.Gear), NewNameData("Aftershock"), NewPowderData(4, nil)}, true},
+		{[]AnyBlock{NewTypeData(types.Gear), NewNameData("Aftershock"), NewDamageData(types.Normal, nil, nil)}, false},
+		{[]AnyBlock{NewTypeData(types.Gear), NewNameData("Aftershock"), NewNameData("Aftershock")}, false},
+		{[]AnyBlock{NewTypeData(types.Gear), NewNameData("Aftershock"), NewShinyData(1, 2), NewShinyData(2, 4)}, true},
+		{[]AnyBlock{NewTypeData(types.Tome)}, false},
+		{[]AnyBlock{NewNameData("Aftershock")}, false},
+		{[]AnyBlock{
+			NewTypeData(types.CraftedConsu),
+			NewCraftedConsumableTypeData(types.Food),
+			NewUsesData(1, 2),
+			NewEffectsData(nil),
+			NewRequirementsData(1, nil, nil),
+			NewPowderData(1, nil),
+		}, false},
+	}
+
+	for _, tc := range testCases {
+		err := ValidateSchema(tc.blocks)
+		if tc.valid && err != nil {
+			t.Errorf("Unexpected schema error for %+v: %v", tc.blocks, err)
+		}
+		if !tc.valid && err == nil {
+			t.Errorf("Expected a schema error for %+v", tc.blocks)
+		}
+	}
+}
+
+func TestEncoderSchemaValidation(t *testing.T) {
+	blocks := []AnyBlock{NewTypeData(types.CraftedConsu), NewNameData("Potion"), NewPowderData(1, nil)}
+
+	_, err := NewItemEncoder().EncodeBlocks(blocks)
+	var encodeErr *encoding.EncodeError
+	if !errors.As(err, &encodeErr) || encodeErr.Type != encoding.ErrSchemaViolation {
+		t.Errorf("Expected a schema violation, got %v", err)
+	}
+
+	idString, err := NewItemEncoder().WithSchemaValidation(false).EncodeBlocks(blocks)
+	if err != nil {
+		t.Fatalf("Unexpected error with schema validation disabled: %v", err)
+	}
+
+	if _, err := NewItemDecoder().DecodeString(idString); err == nil {
+		t.Errorf("Expected a schema violation when decoding")
+	}
+
+	if _, err := NewItemDecoder().WithSchemaValidation(false).DecodeString(idString); err != nil {
+		t.Errorf("Unexpected error with schema validation disabled: %v", err)
+	}
+}
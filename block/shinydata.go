@@ -30,7 +30,7 @@ func (s *ShinyData) EncodeData(ver types.EncodingVersion, out *[]byte) error {
 	*out = append(*out, s.ID)
 
 	// Encode and write the value as a variable-length integer
-	*out = append(*out, encoding.EncodeVarInt(s.Value)...)
+	*out = encoding.AppendVarInt(*out, s.Value)
 
 	return nil
 }
@@ -45,6 +45,11 @@ func (s *ShinyData) Encode(ver types.EncodingVersion, out *[]byte) error {
 
 // DecodeData decodes data for this block from the given bytes
 func (s *ShinyData) DecodeData(bytes []byte, ver types.EncodingVersion) (int, error) {
+	return s.decodeDataWith(bytes, ver, nil)
+}
+
+// decodeDataWith decodes data for this block from the given bytes within the given limits
+func (s *ShinyData) decodeDataWith(bytes []byte, ver types.EncodingVersion, opts *DecodeOptions) (int, error) {
 	if len(bytes) < 1 {
 		return 0, &encoding.DecodeError{Type: encoding.ErrUnexpectedEndOfBytes}
 	}
@@ -55,9 +60,9 @@ func (s *ShinyData) DecodeData(bytes []byte, ver types.EncodingVersion) (int, er
 
 	// Read the value as a variable-length integer
 	var err error
-	s.Value, bytesUsed, err = encoding.DecodeVarInt(bytes[1:])
+	s.Value, bytesUsed, err = opts.decodeVarInt(bytes[1:])
 	if err != nil {
-		return 0, err
+		return 1, err
 	}
 
 	// Return total bytes used (ID byte + varint bytes)
@@ -9,8 +9,14 @@ import (
 // It contains information about the encoding version to be used.
 type StartData struct {
 	Version types.EncodingVersion
+	// Legacy is set if the block holds the legacy version byte 0 instead of the version itself,
+	// so that strings written by early Wynntils releases re-encode byte for byte
+	Legacy bool
 }
 
+// legacyVersionByte is the version byte written by early Wynntils releases
+const legacyVersionByte = 0
+
 // BlockID returns the ID of this block
 func (s *StartData) BlockID() DataBlockID {
 	return BlockStartData
@@ -23,10 +29,26 @@ func (s *StartData) AsID() DataBlockID {
 
 // EncodeData encodes this block's data into the given output buffer
 func (s *StartData) EncodeData(ver types.EncodingVersion, out *[]byte) error {
-	*out = append(*out, byte(s.Version))
+	*out = append(*out, s.WireVersion())
 	return nil
 }
 
+// WireVersion returns the version byte written for this block, a zero value block is written as Version1
+func (s *StartData) WireVersion() byte {
+	if s.Legacy {
+		return legacyVersionByte
+	}
+	return byte(effectiveVersion(s.Version))
+}
+
+// effectiveVersion returns the version an item is encoded with, the zero version stands for Version1
+func effectiveVersion(ver types.EncodingVersion) types.EncodingVersion {
+	if ver == 0 {
+		return types.Version1
+	}
+	return ver
+}
+
 // Encode encodes this block with its ID into the given output buffer
 func (s *StartData) Encode(ver types.EncodingVersion, out *[]byte) error {
 	// Write block ID
@@ -57,12 +79,12 @@ func DecodeStartBytes(bytes []byte) (*StartData, int, error) {
 	ver, err := types.EncodingVersionFromByte(verByte)
 	if err != nil {
 		return nil, 0, &encoding.DecodeError{
-			Type:    encoding.ErrUnknownVersion,
-			Details: verByte,
+			Type: encoding.ErrUnknownVersion,
+			Err:  err,
 		}
 	}
 
-	return &StartData{Version: ver}, 2, nil
+	return &StartData{Version: ver, Legacy: verByte == legacyVersionByte}, 2, nil
 }
 
 // NewStartData creates a new StartData block with the specified version
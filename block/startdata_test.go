@@ -0,0 +1,82 @@
+package block
+
+import (
+	"testing"
+
+	"github.com/AevtJJ/idmangler/types"
+)
+
+func TestStartDataVersionByte(t *testing.T) {
+	testCases := []struct {
+		name   string
+		block  *StartData
+		legacy bool
+		bytes  []byte
+	}{
+		{"zero value", &StartData{}, false, []byte{0, 1}},
+		{"version 1", NewStartData(types.Version1), false, []byte{0, 1}},
+		{"legacy", &StartData{Version: types.Version1, Legacy: true}, true, []byte{0, 0}},
+	}
+
+	for _, tc := range testCases {
+		var bytes []byte
+		if err := tc.block.Encode(types.Version1, &bytes); err != nil {
+			t.Fatalf("%s: Unexpected error: %v", tc.name, err)
+		}
+		if string(bytes) != string(tc.bytes) {
+			t.Errorf("%s: Expected %v, got %v", tc.name, tc.bytes, bytes)
+		}
+
+		decoded, _, err := DecodeStartBytes(bytes)
+		if err != nil {
+			t.Fatalf("%s: Unexpected error: %v", tc.name, err)
+		}
+		if decoded.Version != types.Version1 || decoded.Legacy != tc.legacy {
+			t.Errorf("%s: Expected Version1 with legacy %v, got %v with legacy %v", tc.name, tc.legacy, decoded.Version, decoded.Legacy)
+		}
+	}
+}
+
+func TestEncoderKeepsVersion(t *testing.T) {
+	encoder := NewItemEncoder()
+	blocks := []AnyBlock{&StartData{}, NewTypeData(types.Gear), NewNameData("A")}
+	if _, err := encoder.EncodeBlocks(blocks); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if encoder.version != types.Version1 {
+		t.Errorf("Expected the encoder to keep %v, got %v", types.Version1, encoder.version)
+	}
+
+	idString, err := encoder.EncodeBlocks([]AnyBlock{NewTypeData(types.Gear), NewNameData("A")})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if start := []rune(idString)[0]; start != startCodepointV1 {
+		t.Errorf("Expected the start codepoint %X, got %X", startCodepointV1, start)
+	}
+}
+
+func TestEncoderZeroVersion(t *testing.T) {
+	stats := []*types.Stat{types.NewStat(1, nil, types.NewValueRoll(50))}
+	encoders := []*ItemEncoder{NewItemEncoder(), NewItemEncoder().WithVersion(0)}
+	blocks := [][]AnyBlock{
+		{&StartData{}, NewTypeData(types.Gear), NewNameData("A"), NewIdentificationData(stats, false)},
+		{NewTypeData(types.Gear), NewNameData("A"), NewIdentificationData(stats, false)},
+	}
+
+	for i, encoder := range encoders {
+		idString, err := encoder.EncodeBlocks(blocks[i])
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		decoded, err := NewItemDecoder().DecodeString(idString)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if start := decoded[0].(*StartData); start.Version != types.Version1 {
+			t.Errorf("Expected %v, got %v", types.Version1, start.Version)
+		}
+	}
+}
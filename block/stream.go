@@ -0,0 +1,217 @@
+package block
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+// Codepoints holding the StartData block of an item, with the legacy version byte 0 and with Version1
+const (
+	startCodepointV0 = rune(encoding.AreaA)
+	startCodepointV1 = rune(encoding.AreaA + 1)
+)
+
+// StreamError represents an item in a stream which could not be decoded
+type StreamError struct {
+	// Codepoint is the index of the first codepoint of the item within the stream
+	Codepoint int
+	// Err is the error which stopped decoding the item
+	Err error
+}
+
+// Error returns the error message for an item which could not be decoded
+func (e *StreamError) Error() string {
+	return fmt.Sprintf("item at codepoint %d: %v", e.Codepoint, e.Err)
+}
+
+// Unwrap returns the error which stopped decoding the item
+func (e *StreamError) Unwrap() error {
+	return e.Err
+}
+
+// StreamDecoder decodes the items of a stream of codepoints one at a time.
+// Anything between items, such as chat text, is skipped. Only the codepoints of the item
+// being decoded are kept in memory, so arbitrarily large inputs are decoded in constant memory.
+type StreamDecoder struct {
+	reader  io.RuneReader
+	decoder *ItemDecoder
+	// pending holds codepoints to read again after resynchronizing
+	pending []rune
+	// runes and bytes hold the codepoints and the decoded data of the current item
+	runes []rune
+	bytes []byte
+	// pos is the index of the next codepoint within the stream
+	pos int
+}
+
+// NewStreamDecoder creates a new StreamDecoder reading from the given reader.
+// Items are decoded with the settings of the given decoder, or those of NewItemDecoder if it is nil.
+func NewStreamDecoder(reader io.RuneReader, decoder *ItemDecoder) *StreamDecoder {
+	if decoder == nil {
+		decoder = NewItemDecoder()
+	}
+	return &StreamDecoder{
+		reader:  reader,
+		decoder: decoder,
+	}
+}
+
+// Next decodes the next item of the stream. An item is a StartData codepoint followed by
+// blocks up to and including EndData. If an item can't be decoded a *StreamError is returned and
+// the following call continues with the next StartData codepoint after the start of the broken item.
+// Returns io.EOF once the stream has no more items.
+func (s *StreamDecoder) Next() ([]AnyBlock, error) {
+	for {
+		r, err := s.read()
+		if err != nil {
+			return nil, err
+		}
+		if r == startCodepointV0 || r == startCodepointV1 {
+			return s.decodeItem(r)
+		}
+	}
+}
+
+// read returns the next codepoint of the stream
+func (s *StreamDecoder) read() (rune, error) {
+	if len(s.pending) > 0 {
+		r := s.pending[0]
+		s.pending = s.pending[1:]
+		s.pos++
+		return r, nil
+	}
+
+	r, _, err := s.reader.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	s.pos++
+	return r, nil
+}
+
+// decodeItem reads the codepoints of the item starting with the given StartData codepoint
+// block by block until its EndData block
+func (s *StreamDecoder) decodeItem(start rune) ([]AnyBlock, error) {
+	startPos := s.pos - 1
+	s.runes = append(s.runes[:0], start)
+	s.bytes = append(s.bytes[:0], byte(BlockStartData), byte(start-startCodepointV0))
+	// Both start codepoints hold a version laid out like Version1
+	ver := types.Version1
+
+	opts := &s.decoder.options
+	parsed := 2
+	blocks := 1
+	for {
+		r, err := s.read()
+		if err == io.EOF {
+			return nil, s.fail(startPos, &encoding.DecoderError{
+				ErrorData: &encoding.DecodeError{Type: encoding.ErrMissingEndData},
+				Offset:    len(s.bytes),
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		decoded, err := encoding.DecodeChar(r)
+		if err != nil {
+			if badCodepoint, ok := err.(*encoding.BadCodepointError); ok {
+				badCodepoint.Index = len(s.runes)
+			}
+			return nil, s.fail(startPos, codepointError(err))
+		}
+		s.runes = append(s.runes, r)
+		s.bytes = append(s.bytes, decoded...)
+
+		if exceeds(len(s.bytes), opts.MaxAllocation) {
+			return nil, s.fail(startPos, limitDecoderError(limitError("allocation", len(s.bytes), opts.MaxAllocation), -1))
+		}
+
+		// Decode every block which is complete by now
+		for parsed < len(s.bytes) {
+			block, n, err := decodeBlock(ver, s.bytes[parsed:], opts)
+			if errors.Is(err, encoding.ErrUnexpectedEndOfBytes) {
+				// The block continues in the next codepoints
+				break
+			}
+			if err != nil {
+				return nil, s.fail(startPos, shiftError(err, parsed))
+			}
+
+			parsed += n
+			blocks++
+			if err := opts.checkBlocks(blocks - 1); err != nil {
+				return nil, s.fail(startPos, limitDecoderError(err, parsed-n))
+			}
+
+			// The item is complete, decode it with all checks of the decoder
+			if block.AsID() == BlockEndData {
+				decodedBlocks, err := s.decoder.DecodeBytes(s.bytes)
+				if err != nil {
+					return nil, s.fail(startPos, err)
+				}
+				return decodedBlocks, nil
+			}
+		}
+	}
+}
+
+// fail wraps the error of the item starting at the given position and resynchronizes
+// the stream on the next StartData codepoint after the start of the item
+func (s *StreamDecoder) fail(startPos int, err error) error {
+	for i := 1; i < len(s.runes); i++ {
+		if s.runes[i] == startCodepointV0 || s.runes[i] == startCodepointV1 {
+			replay := make([]rune, 0, len(s.runes)-i+len(s.pending))
+			replay = append(replay, s.runes[i:]...)
+			s.pending = append(replay, s.pending...)
+			s.pos = startPos + i
+			break
+		}
+	}
+
+	return &StreamError{Codepoint: startPos, Err: err}
+}
+
+// StreamEncoder writes the ID strings of items to a writer one at a time.
+// Its buffers are reused between items, so once they have grown to the size of the largest item
+// encoding an item does not allocate.
+type StreamEncoder struct {
+	writer  io.Writer
+	encoder *ItemEncoder
+	// data holds the encoded bytes and out the UTF-8 ID string of the current item
+	data []byte
+	out  []byte
+}
+
+// NewStreamEncoder creates a new StreamEncoder writing to the given writer.
+// Items are encoded with the settings of the given encoder, or those of NewItemEncoder if it is nil.
+func NewStreamEncoder(writer io.Writer, encoder *ItemEncoder) *StreamEncoder {
+	if encoder == nil {
+		encoder = NewItemEncoder()
+	}
+	return &StreamEncoder{
+		writer:  writer,
+		encoder: encoder,
+	}
+}
+
+// Encode encodes a series of blocks and writes the ID string to the writer
+func (s *StreamEncoder) Encode(blocks []AnyBlock) error {
+	s.data = s.data[:0]
+	if err := s.encoder.encodeBlocks(blocks, &s.data); err != nil {
+		return err
+	}
+
+	// Size the output for the whole ID string up front
+	if n := encoding.EncodedLength(s.data); cap(s.out) < n {
+		s.out = make([]byte, 0, n)
+	}
+	s.out = encoding.AppendString(s.out[:0], s.data)
+
+	_, err := s.writer.Write(s.out)
+	return err
+}
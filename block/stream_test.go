@@ -0,0 +1,175 @@
+package block
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+func TestStreamDecoder(t *testing.T) {
+	seeds := seedItems(t)
+	items := make([]string, len(seeds))
+	for i, seed := range seeds {
+		items[i] = encoding.EncodeString(seed)
+	}
+	truncated := string([]rune(items[0])[:5])
+
+	testCases := []struct {
+		name  string
+		input string
+		// results holds the number of blocks of each item, or -1 for an item which fails
+		results []int
+		// errors and starts hold the kind and the codepoint index of each failed item
+		errors []encoding.ErrorKind
+		starts []int
+	}{
+		{"empty", "", nil, nil, nil},
+		{"only text", "hello world", nil, nil, nil},
+		{"single item", items[0], []int{8}, nil, nil},
+		{"items between text", "<player> look " + items[0] + " and " + items[1] + items[2] + "!", []int{8, 10, 7}, nil, nil},
+		{"truncated item", "a" + truncated + items[1], []int{-1, 10}, []encoding.ErrorKind{encoding.ErrStartReparse}, []int{1}},
+		{"unexpected end", items[2] + " " + truncated, []int{7, -1}, []encoding.ErrorKind{encoding.ErrMissingEndData}, []int{len([]rune(items[2])) + 1}},
+		{"bad codepoint", truncated + "x" + items[2], []int{-1, 7}, []encoding.ErrorKind{encoding.ErrBadCodepoint}, []int{0}},
+	}
+
+	for _, tc := range testCases {
+		stream := NewStreamDecoder(strings.NewReader(tc.input), nil)
+		failed := 0
+		for i, expected := range tc.results {
+			blocks, err := stream.Next()
+			if expected >= 0 {
+				if err != nil || len(blocks) != expected {
+					t.Errorf("%s: item %d: Expected %d blocks, got %d (%v)", tc.name, i, expected, len(blocks), err)
+				}
+				continue
+			}
+
+			var streamErr *StreamError
+			if !errors.As(err, &streamErr) {
+				t.Errorf("%s: item %d: Expected a stream error, got %v", tc.name, i, err)
+			} else if !errors.Is(err, tc.errors[failed]) || streamErr.Codepoint != tc.starts[failed] {
+				t.Errorf("%s: item %d: Expected %v at codepoint %d, got %v", tc.name, i, tc.errors[failed], tc.starts[failed], err)
+			}
+			failed++
+		}
+
+		if _, err := stream.Next(); err != io.EOF {
+			t.Errorf("%s: Expected io.EOF after all items, got %v", tc.name, err)
+		}
+	}
+}
+
+func TestStreamDecoderResync(t *testing.T) {
+	item := encoding.EncodeString(seedItems(t)[0])
+
+	// An item cut off right before another one starts resumes at the start of the next item
+	input := string([]rune(item)[:3]) + item
+	stream := NewStreamDecoder(strings.NewReader(input), NewItemDecoder().WithStrict(true))
+
+	if _, err := stream.Next(); err == nil {
+		t.Errorf("Expected the cut off item to fail")
+	}
+	blocks, err := stream.Next()
+	if err != nil {
+		t.Fatalf("Expected the second item to be decoded, got %v", err)
+	}
+	var bytes []byte
+	for _, b := range blocks {
+		if err := b.Encode(types.Version1, &bytes); err != nil {
+			t.Fatalf("Error re-encoding decoded %T: %v", b, err)
+		}
+	}
+	if reencoded := encoding.EncodeString(bytes); reencoded != item {
+		t.Errorf("Expected %q, got %q", item, reencoded)
+	}
+	if _, err := stream.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+}
+
+// seedBlocks decodes the seed items into their blocks
+func seedBlocks(tb testing.TB) [][]AnyBlock {
+	seeds := seedItems(tb)
+	items := make([][]AnyBlock, len(seeds))
+	for i, seed := range seeds {
+		blocks, err := NewItemDecoder().DecodeBytes(seed)
+		if err != nil {
+			tb.Fatalf("Error decoding seed %d: %v", i, err)
+		}
+		items[i] = blocks
+	}
+	return items
+}
+
+func TestStreamEncoder(t *testing.T) {
+	items := seedBlocks(t)
+
+	var out strings.Builder
+	var expected string
+	encoder := NewStreamEncoder(&out, nil)
+	for _, blocks := range items {
+		idString, err := NewItemEncoder().EncodeBlocks(blocks)
+		if err != nil {
+			t.Fatalf("Error encoding %v: %v", blocks, err)
+		}
+		expected += idString
+
+		if err := encoder.Encode(blocks); err != nil {
+			t.Errorf("Error stream encoding %v: %v", blocks, err)
+		}
+	}
+
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+
+	// Blocks breaking the schema are not written
+	if err := encoder.Encode([]AnyBlock{NewNameData("No type")}); !errors.Is(err, encoding.ErrSchemaViolation) {
+		t.Errorf("Expected %v, got %v", encoding.ErrSchemaViolation, err)
+	}
+	if out.String() != expected {
+		t.Errorf("Expected nothing to be written for a failed item, got %q", out.String())
+	}
+}
+
+func TestStreamEncoderAllocations(t *testing.T) {
+	items := seedBlocks(t)
+	encoder := NewStreamEncoder(io.Discard, nil)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		for _, blocks := range items {
+			if err := encoder.Encode(blocks); err != nil {
+				t.Fatalf("Error encoding %v: %v", blocks, err)
+			}
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("Expected no allocations in steady state, got %v", allocs)
+	}
+}
+
+func BenchmarkEncodeBlocks(b *testing.B) {
+	items := seedBlocks(b)
+	encoder := NewItemEncoder()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := encoder.EncodeBlocks(items[i%len(items)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStreamEncoder(b *testing.B) {
+	items := seedBlocks(b)
+	encoder := NewStreamEncoder(io.Discard, nil)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := encoder.Encode(items[i%len(items)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package block
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AevtJJ/idmangler/encoding"
+)
+
+func TestDecodeStrict(t *testing.T) {
+	testCases := []struct {
+		name   string
+		bytes  []byte
+		kind   encoding.ErrorKind
+		offset int
+	}{
+		{"trailing bytes", []byte{0, 1, 1, 0, 2, 'A', 0, 255, 7, 7}, encoding.ErrTrailingBytes, 8},
+		{"missing end", []byte{0, 1, 1, 0, 2, 'A', 0}, encoding.ErrMissingEndData, 7},
+		{"duplicate type", []byte{0, 1, 1, 0, 1, 0, 2, 'A', 0, 255}, encoding.ErrDuplicateBlock, 4},
+		{"duplicate name", []byte{0, 1, 1, 0, 2, 'A', 0, 2, 'B', 0, 255}, encoding.ErrDuplicateBlock, 7},
+		{"duplicate reroll", []byte{0, 1, 1, 0, 2, 'A', 0, 5, 1, 5, 2, 255}, encoding.ErrDuplicateBlock, 9},
+	}
+
+	for _, tc := range testCases {
+		input := encoding.EncodeString(tc.bytes)
+
+		if _, err := NewItemDecoder().WithSchemaValidation(false).DecodeString(input); err != nil {
+			t.Errorf("%s: expected the default decoder to accept the input, got %v", tc.name, err)
+		}
+
+		_, err := NewItemDecoder().WithSchemaValidation(false).WithStrict(true).DecodeString(input)
+		if !errors.Is(err, tc.kind) {
+			t.Errorf("%s: expected %v, got %v", tc.name, tc.kind, err)
+			continue
+		}
+
+		var decoderErr *encoding.DecoderError
+		if !errors.As(err, &decoderErr) || decoderErr.Offset != tc.offset {
+			t.Errorf("%s: expected offset %d, got %v", tc.name, tc.offset, err)
+		}
+	}
+
+	// Shiny blocks may be repeated
+	valid := []byte{0, 1, 1, 0, 2, 'A', 0, 6, 1, 2, 6, 2, 4, 255}
+	if _, err := NewItemDecoder().WithStrict(true).DecodeString(encoding.EncodeString(valid)); err != nil {
+		t.Errorf("Expected repeated ShinyData to be accepted, got %v", err)
+	}
+}
@@ -43,8 +43,8 @@ func (t *TypeData) DecodeData(bytes []byte, ver types.EncodingVersion) (int, err
 	itemType, err := types.ItemTypeFromByte(bytes[0])
 	if err != nil {
 		return 0, &encoding.DecodeError{
-			Type:    encoding.ErrBadItemType,
-			Details: bytes[0],
+			Type: encoding.ErrBadItemType,
+			Err:  err,
 		}
 	}
 
@@ -0,0 +1,85 @@
+package block
+
+import (
+	"fmt"
+
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+// UsesData represents the uses block of a crafted consumable in an encoded item string.
+// It stores how many charges the consumable has left out of its maximum.
+type UsesData struct {
+	// Current is the number of uses remaining
+	Current byte
+	// Max is the maximum number of uses
+	Max byte
+}
+
+// BlockID returns the ID of this block
+func (u *UsesData) BlockID() DataBlockID {
+	return BlockUsesData
+}
+
+// AsID returns the ID of this block
+func (u *UsesData) AsID() DataBlockID {
+	return u.BlockID()
+}
+
+// EncodeData encodes this block's data into the given output buffer
+func (u *UsesData) EncodeData(ver types.EncodingVersion, out *[]byte) error {
+	if u.Current > u.Max {
+		return &encoding.EncodeError{
+			Type: encoding.ErrInvalidUses,
+			Err:  usesError(u.Current, u.Max),
+		}
+	}
+
+	// Write the remaining and maximum uses
+	*out = append(*out, u.Current, u.Max)
+	return nil
+}
+
+// Encode encodes this block with its ID into the given output buffer
+func (u *UsesData) Encode(ver types.EncodingVersion, out *[]byte) error {
+	// Write block ID
+	*out = append(*out, byte(u.BlockID()))
+	// Write block data
+	return u.EncodeData(ver, out)
+}
+
+// DecodeData decodes data for this block from the given bytes
+func (u *UsesData) DecodeData(bytes []byte, ver types.EncodingVersion) (int, error) {
+	if len(bytes) < 2 {
+		return 0, &encoding.DecodeError{Type: encoding.ErrUnexpectedEndOfBytes}
+	}
+
+	if bytes[0] > bytes[1] {
+		return 0, &encoding.DecodeError{
+			Type: encoding.ErrInvalidUses,
+			Err:  usesError(bytes[0], bytes[1]),
+		}
+	}
+
+	// Read the remaining and maximum uses
+	u.Current = bytes[0]
+	u.Max = bytes[1]
+	return 2, nil
+}
+
+// usesError describes remaining uses exceeding the maximum uses
+func usesError(current byte, max byte) error {
+	return &encoding.ValueError{
+		Name:   "remaining uses",
+		Value:  int64(current),
+		Reason: fmt.Sprintf("exceed maximum uses %d", max),
+	}
+}
+
+// NewUsesData creates a new UsesData block with the specified remaining and maximum uses
+func NewUsesData(current byte, max byte) *UsesData {
+	return &UsesData{
+		Current: current,
+		Max:     max,
+	}
+}
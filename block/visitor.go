@@ -0,0 +1,125 @@
+package block
+
+import (
+	"github.com/AevtJJ/idmangler/encoding"
+)
+
+// BlockVisitor receives the blocks of an item one at a time while it is decoded,
+// so items can be filtered or counted without collecting their blocks
+type BlockVisitor interface {
+	// OnStart is called with the StartData block before any other block.
+	// Returning false stops decoding the item.
+	OnStart(start *StartData) bool
+	// OnBlock is called with every following block in encoded order, including EndData.
+	// Returning false stops decoding the item.
+	OnBlock(block AnyBlock) bool
+	// OnError is called with the error which stopped decoding the item, including schema violations.
+	// Blocks decoded before the error have already been passed to the visitor.
+	OnError(err error)
+	// OnEnd is called once the item is done, after all blocks were visited, the visitor stopped early
+	// or OnError was called
+	OnEnd()
+}
+
+// NopBlockVisitor is a BlockVisitor which visits every block and ignores it.
+// It can be embedded to implement only some of the methods of a BlockVisitor.
+type NopBlockVisitor struct{}
+
+// OnStart continues with the blocks after StartData
+func (v NopBlockVisitor) OnStart(start *StartData) bool {
+	return true
+}
+
+// OnBlock continues with the next block
+func (v NopBlockVisitor) OnBlock(block AnyBlock) bool {
+	return true
+}
+
+// OnError ignores the error
+func (v NopBlockVisitor) OnError(err error) {
+}
+
+// OnEnd does nothing
+func (v NopBlockVisitor) OnEnd() {
+}
+
+// Visit decodes an ID string and passes each block to the visitor as soon as it is decoded.
+// With schema validation every block is checked against the schema of the item type before it is
+// passed on, missing blocks are reported once all blocks were visited.
+// Returns the error which stopped decoding, or nil if the item was decoded or the visitor stopped early.
+func (d *ItemDecoder) Visit(idString string, visitor BlockVisitor) error {
+	return visitDone(visitor, d.visitString(idString, visitor))
+}
+
+// VisitBytes decodes the data of an ID string and passes each block to the visitor as soon as it is decoded
+func (d *ItemDecoder) VisitBytes(bytes []byte, visitor BlockVisitor) error {
+	return visitDone(visitor, d.visitBytes(bytes, visitor))
+}
+
+// visitString converts an ID string to bytes and visits its blocks
+func (d *ItemDecoder) visitString(idString string, visitor BlockVisitor) error {
+	// Refuse input which exceeds the limits before allocating anything for it
+	if err := d.options.checkInput(idString); err != nil {
+		return limitDecoderError(err, -1)
+	}
+
+	// Convert string to bytes
+	bytes, err := encoding.DecodeString(idString)
+	if err != nil {
+		return codepointError(err)
+	}
+
+	return d.visitBytes(bytes, visitor)
+}
+
+// visitBytes passes the blocks decoded from the given bytes to the visitor until it stops
+func (d *ItemDecoder) visitBytes(bytes []byte, visitor BlockVisitor) error {
+	if exceeds(len(bytes), d.options.MaxAllocation) {
+		return limitDecoderError(limitError("allocation", len(bytes), d.options.MaxAllocation), -1)
+	}
+
+	// Start by decoding the start block to get the version
+	startBlock, bytesRead, err := DecodeStartBytes(bytes)
+	if err != nil {
+		return newBlockError(BlockStartData, 0, err)
+	}
+	if !visitor.OnStart(startBlock) {
+		return nil
+	}
+
+	// Check each block against the schema before passing it on
+	var checker schemaChecker
+	var schemaErr error
+	visit := func(block AnyBlock, offset int) bool {
+		if d.validateSchema {
+			if schemaErr = checker.check(block); schemaErr != nil {
+				return false
+			}
+		}
+		return visitor.OnBlock(block)
+	}
+
+	_, stopped, err := d.walkBlocks(startBlock.Version, bytes[bytesRead:], visit)
+	if err != nil {
+		return shiftError(err, bytesRead)
+	}
+	if schemaErr != nil {
+		return schemaDecoderError(schemaErr)
+	}
+	if !stopped && d.validateSchema {
+		if err := checker.finish(); err != nil {
+			return schemaDecoderError(err)
+		}
+	}
+
+	return nil
+}
+
+// visitDone passes the error which stopped decoding to the visitor if there is one, ends the item and returns the error
+func visitDone(visitor BlockVisitor, err error) error {
+	if err != nil {
+		visitor.OnError(err)
+	}
+	visitor.OnEnd()
+	return err
+}
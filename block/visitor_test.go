@@ -0,0 +1,120 @@
+package block
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+// recordingVisitor records the calls of a decoder and stops at the start or after a block
+// which isn't gear typed if asked to
+type recordingVisitor struct {
+	startOnly bool
+	gearOnly  bool
+	events    []string
+}
+
+func (v *recordingVisitor) OnStart(start *StartData) bool {
+	v.events = append(v.events, "start")
+	return !v.startOnly
+}
+
+func (v *recordingVisitor) OnBlock(block AnyBlock) bool {
+	v.events = append(v.events, block.AsID().String())
+	if typeData, ok := block.(*TypeData); ok && v.gearOnly {
+		return typeData.ItemType == types.Gear
+	}
+	return true
+}
+
+func (v *recordingVisitor) OnError(err error) {
+	v.events = append(v.events, "error")
+}
+
+func (v *recordingVisitor) OnEnd() {
+	v.events = append(v.events, "end")
+}
+
+func TestVisit(t *testing.T) {
+	seeds := seedItems(t)
+
+	testCases := []struct {
+		name      string
+		bytes     []byte
+		startOnly bool
+		gearOnly  bool
+		schema    bool
+		events    string
+		kind      error
+	}{
+		{"gear", seeds[0], false, false, true, "start TypeData NameData IdentificationData PowderData RerollData ShinyData EndData end", nil},
+		{"gear only", seeds[0], false, true, true, "start TypeData NameData IdentificationData PowderData RerollData ShinyData EndData end", nil},
+		{"crafted gear only", seeds[1], false, true, true, "start TypeData end", nil},
+		{"start only", seeds[0], true, false, true, "start end", nil},
+		{"truncated", seeds[0][:19], false, false, true, "start TypeData NameData error end", encoding.ErrUnexpectedEndOfBytes},
+		{"no start", []byte{1}, false, false, true, "error end", encoding.ErrUnexpectedEndOfBytes},
+		{"repeated block", []byte{0, 1, 1, 0, 2, 'A', 0, 5, 1, 5, 1, 255}, false, false, true, "start TypeData NameData RerollData error end", encoding.ErrSchemaViolation},
+		{"repeated block without schema", []byte{0, 1, 1, 0, 2, 'A', 0, 5, 1, 5, 1, 255}, false, false, false, "start TypeData NameData RerollData RerollData EndData end", nil},
+		{"missing block", []byte{0, 1, 1, 0, 255}, false, false, true, "start TypeData EndData error end", encoding.ErrSchemaViolation},
+		{"stopped before missing block", []byte{0, 1, 1, 3, 255}, false, true, true, "start TypeData end", nil},
+	}
+
+	for _, tc := range testCases {
+		visitor := &recordingVisitor{startOnly: tc.startOnly, gearOnly: tc.gearOnly}
+		err := NewItemDecoder().WithSchemaValidation(tc.schema).Visit(encoding.EncodeString(tc.bytes), visitor)
+
+		if events := strings.Join(visitor.events, " "); events != tc.events {
+			t.Errorf("%s: Expected %q, got %q", tc.name, tc.events, events)
+		}
+		if tc.kind == nil && err != nil {
+			t.Errorf("%s: Expected no error, got %v", tc.name, err)
+		}
+		if tc.kind != nil && !errors.Is(err, tc.kind) {
+			t.Errorf("%s: Expected %v, got %v", tc.name, tc.kind, err)
+		}
+	}
+}
+
+func TestVisitMatchesDecode(t *testing.T) {
+	for i, seed := range seedItems(t) {
+		expected, err := NewItemDecoder().DecodeBytes(seed)
+		if err != nil {
+			t.Fatalf("Error decoding seed %d: %v", i, err)
+		}
+
+		var visited []AnyBlock
+		visitor := &collectingVisitor{blocks: &visited}
+		if err := NewItemDecoder().VisitBytes(seed, visitor); err != nil {
+			t.Errorf("Error visiting seed %d: %v", i, err)
+		}
+
+		if len(visited) != len(expected) {
+			t.Errorf("Seed %d: Expected %d blocks, got %d", i, len(expected), len(visited))
+			continue
+		}
+		for j := range expected {
+			if visited[j].AsID() != expected[j].AsID() {
+				t.Errorf("Seed %d block %d: Expected %v, got %v", i, j, expected[j].AsID(), visited[j].AsID())
+			}
+		}
+	}
+}
+
+// collectingVisitor collects all blocks, only implementing the methods it needs
+type collectingVisitor struct {
+	NopBlockVisitor
+	blocks *[]AnyBlock
+}
+
+func (v *collectingVisitor) OnStart(start *StartData) bool {
+	*v.blocks = append(*v.blocks, start)
+	return true
+}
+
+func (v *collectingVisitor) OnBlock(block AnyBlock) bool {
+	*v.blocks = append(*v.blocks, block)
+	return true
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/AevtJJ/idmangler"
+	"github.com/AevtJJ/idmangler/block"
+	"github.com/AevtJJ/idmangler/encoding"
+)
+
+// ANSI escape sequences used for coloring the dump
+const (
+	colorReset     = "\x1b[0m"
+	colorDim       = "\x1b[2m"
+	colorBold      = "\x1b[1m"
+	colorError     = "\x1b[1;31m"
+	colorHighlight = "\x1b[1;37;41m"
+)
+
+// blockColors are cycled through to tell neighbouring blocks apart
+var blockColors = []string{"\x1b[36m", "\x1b[33m", "\x1b[32m", "\x1b[35m", "\x1b[34m"}
+
+// explainCommand prints an annotated hex dump of the ID strings given as arguments or read line by line from stdin
+func explainCommand(args []string) int {
+	flags := flag.NewFlagSet("explain", flag.ExitOnError)
+	noColor := flags.Bool("no-color", false, "disable colored output")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: idmangler explain [-no-color] [id string...]")
+		fmt.Fprintln(flags.Output(), "Prints an annotated hex dump of every ID string, read from stdin if none are given.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	inputs := flags.Args()
+	if len(inputs) == 0 {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				inputs = append(inputs, line)
+			}
+		}
+	}
+
+	d := &dumper{out: os.Stdout, color: !*noColor}
+	status := 0
+	for i, idString := range inputs {
+		if i > 0 {
+			fmt.Fprintln(d.out)
+		}
+		if !d.explain(idString) {
+			status = 1
+		}
+	}
+
+	return status
+}
+
+// dumper writes annotated hex dumps
+type dumper struct {
+	out   io.Writer
+	color bool
+}
+
+// paint wraps text in a color if coloring is enabled
+func (d *dumper) paint(color, text string) string {
+	if !d.color {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// explain dumps a single ID string and returns whether it decoded without errors
+func (d *dumper) explain(idString string) bool {
+	trace := idmangler.Inspect(idString)
+
+	// The byte at which decoding failed, -1 if unknown
+	failOffset := -1
+	var decoderErr *encoding.DecoderError
+	if errors.As(trace.Err, &decoderErr) {
+		failOffset = decoderErr.Offset
+	}
+
+	for i, node := range trace.Blocks {
+		color := blockColors[i%len(blockColors)]
+		header := d.paint(colorBold, node.Name) + summary(node)
+		if node.Err != nil {
+			color = colorError
+			header = d.paint(colorError, node.Name+" failed")
+		}
+
+		// Nodes without fields, such as trailing bytes, are dumped whole
+		if len(node.Children) == 0 {
+			d.rows(node.Start, node.Raw, failOffset, d.paint(color, node.Name))
+			continue
+		}
+
+		d.rows(node.Start, node.Children[0].Raw, failOffset, header)
+		for _, field := range node.Children[1:] {
+			d.field(field, color, 1, failOffset)
+		}
+	}
+
+	if trace.Err != nil {
+		fmt.Fprintln(d.out, d.paint(colorError, "error: "+trace.Err.Error()))
+		return false
+	}
+	return true
+}
+
+// field dumps a field and its parts, highlighting the byte at the given offset
+func (d *dumper) field(node *block.TraceNode, color string, depth int, highlight int) {
+	label := strings.Repeat("  ", depth) + node.Name
+	if node.BitLength > 0 {
+		label += fmt.Sprintf(" [bits %d+%d]", node.BitOffset, node.BitLength)
+	}
+	if node.Value != nil {
+		label += fmt.Sprintf(" = %v", node.Value)
+	}
+
+	// Fields with parts only get a header line, the bytes are shown with the parts
+	if len(node.Children) > 0 {
+		d.line(node.Start, nil, color, label)
+		for _, child := range node.Children {
+			d.field(child, color, depth+1, highlight)
+		}
+		return
+	}
+
+	d.rows(node.Start, node.Raw, highlight, d.paint(color, label))
+}
+
+// line writes dump lines for the given bytes
+func (d *dumper) line(offset int, raw []byte, color, label string) {
+	d.rows(offset, raw, -1, d.paint(color, label))
+}
+
+// rows writes the given bytes in rows of eight, highlighting the byte at the given offset.
+// The label is written next to the first row.
+func (d *dumper) rows(offset int, raw []byte, highlight int, label string) {
+	for start := 0; start < len(raw) || start == 0; start += 8 {
+		end := start + 8
+		if end > len(raw) {
+			end = len(raw)
+		}
+
+		d.row(offset+start, raw[start:end], highlight, label)
+		label = ""
+	}
+}
+
+// row writes a single dump line of at most eight bytes
+func (d *dumper) row(offset int, raw []byte, highlight int, label string) {
+	var hex strings.Builder
+	for i, b := range raw {
+		text := fmt.Sprintf("%02x", b)
+		if offset+i == highlight {
+			text = d.paint(colorHighlight, text)
+		}
+		hex.WriteString(text)
+		hex.WriteByte(' ')
+	}
+
+	// Pad the hex column, the escape sequences take no space on screen
+	padding := strings.Repeat(" ", 24-len(raw)*3)
+
+	position := d.paint(colorDim, fmt.Sprintf("%04x cp%-3d", offset, encoding.CodepointIndex(offset)))
+	if label != "" {
+		label = " " + label
+	}
+	fmt.Fprintf(d.out, "%s %s%s|%s\n", position, hex.String(), padding, label)
+}
+
+// summary lists the single byte fields of a block, such as "count=5 extended=1"
+func summary(node *block.TraceNode) string {
+	if len(node.Children) == 0 {
+		return ""
+	}
+
+	var parts []string
+	for _, field := range node.Children[1:] {
+		if len(field.Raw) == 1 && len(field.Children) == 0 && field.Value != nil && !strings.Contains(field.Name, " ") {
+			parts = append(parts, fmt.Sprintf("%s=%v", field.Name, field.Value))
+		}
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+	return " " + strings.Join(parts, " ")
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AevtJJ/idmangler/encoding"
+)
+
+func TestExplain(t *testing.T) {
+	testCases := []struct {
+		name     string
+		bytes    []byte
+		ok       bool
+		expected []string
+	}{
+		{"item", []byte{0, 1, 1, 0, 2, 'A', 0, 255}, true, []string{"StartData version=", "TypeData", "NameData", "name = A", "EndData"}},
+		{"trailing bytes", []byte{0, 1, 1, 0, 2, 'A', 0, 255, 7, 7}, true, []string{"EndData", "0008 cp4   07 07", "TrailingBytes"}},
+		{"failed block", []byte{0, 1, 1, 3, 9, 60, 9, 0, 255}, false, []string{"RequirementsData failed", "level = 60", "class", "unparsed", "error: "}},
+		{"truncated varint", []byte{0, 1, 1, 0, 11, 0x80}, false, []string{"DefenseData failed", "health", "error: "}},
+		{"no start", []byte{1}, false, []string{"StartData failed", "error: "}},
+	}
+
+	for _, tc := range testCases {
+		var out strings.Builder
+		d := &dumper{out: &out, color: false}
+		ok := d.explain(encoding.EncodeString(tc.bytes))
+
+		if ok != tc.ok {
+			t.Errorf("%s: Expected %v, got %v", tc.name, tc.ok, ok)
+		}
+		if strings.Contains(out.String(), "\x1b[") {
+			t.Errorf("%s: Expected no escape sequences without color, got %q", tc.name, out.String())
+		}
+		for _, text := range tc.expected {
+			if !strings.Contains(out.String(), text) {
+				t.Errorf("%s: Expected %q in the dump, got:\n%s", tc.name, text, out.String())
+			}
+		}
+	}
+}
+
+func TestExplainColor(t *testing.T) {
+	var out strings.Builder
+	d := &dumper{out: &out, color: true}
+	d.explain(encoding.EncodeString([]byte{0, 1, 1, 3, 9, 60, 9, 0, 255}))
+
+	// The offending class byte is highlighted
+	if !strings.Contains(out.String(), colorHighlight+"09"+colorReset) {
+		t.Errorf("Expected the class byte to be highlighted, got %q", out.String())
+	}
+}
@@ -3,3 +3,5 @@ module test
 go 1.24.6
 
 require github.com/AevtJJ/idmangler v0.0.0-20250909031117-e6d2cbc3bbb9
+
+replace github.com/AevtJJ/idmangler => ../
@@ -1,16 +1,32 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"os"
 
 	"github.com/AevtJJ/idmangler"
 )
 
+// sample is a real item string which is decoded when no command is given
+const sample = "󰀀󰄀󰉁󶙴󶕲󷍨󶽣󶬀󰌅󰀸󵴉󶱈󵌊󵌃󷰄󰐄󳆌󶀅󰋿"
+
 func main() {
-	item, error := idmangler.DecodeItemObject("󰀀󰄀󰉁󶙴󶕲󷍨󶽣󶬀󰌅󰀸󵴉󶱈󵌊󵌃󷰄󰐄󳆌󶀅󰋿")
-	if error != nil {
-		panic(error)
+	if len(os.Args) > 1 && os.Args[1] == "explain" {
+		os.Exit(explainCommand(os.Args[2:]))
+	}
+
+	flag.Parse()
+	idString := sample
+	if flag.NArg() > 0 {
+		idString = flag.Arg(0)
+	}
+
+	item, err := idmangler.DecodeItemObject(idString)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 
 	fmt.Println(item)
-}
\ No newline at end of file
+}
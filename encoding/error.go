@@ -4,10 +4,16 @@ import (
 	"fmt"
 )
 
-// Error types
+// ErrorKind identifies the kind of an encoding or decoding failure.
+// Every kind is an error itself, so the kinds can be used as sentinel values with errors.Is:
+//
+//	if errors.Is(err, encoding.ErrUnexpectedEndOfBytes) { ... }
+type ErrorKind int
+
+// Error kinds
 const (
 	// ErrUnexpectedEndOfBytes indicates that the byte array ended unexpectedly
-	ErrUnexpectedEndOfBytes = iota
+	ErrUnexpectedEndOfBytes ErrorKind = iota
 	// ErrStartReparse indicates that a start block was encountered during reparsing
 	ErrStartReparse
 	// ErrUnknownBlock indicates that an unknown block ID was encountered
@@ -36,93 +42,263 @@ const (
 	ErrBadElement
 	// ErrBadPowderTier indicates an invalid powder tier
 	ErrBadPowderTier
+	// ErrBadGearType indicates an invalid crafted gear type
+	ErrBadGearType
+	// ErrInvalidDurability indicates that the current durability exceeds the maximum durability
+	ErrInvalidDurability
+	// ErrBadClassType indicates an invalid class type
+	ErrBadClassType
+	// ErrBadSkillType indicates an invalid skill type
+	ErrBadSkillType
+	// ErrInvalidSkillRequirement indicates a negative or duplicated skill point requirement
+	ErrInvalidSkillRequirement
+	// ErrBadAttackSpeed indicates an invalid attack speed
+	ErrBadAttackSpeed
+	// ErrInvalidDamage indicates an invalid or duplicated damage range
+	ErrInvalidDamage
+	// ErrBadConsumableType indicates an invalid consumable type
+	ErrBadConsumableType
+	// ErrInvalidUses indicates that the remaining uses exceed the maximum uses
+	ErrInvalidUses
+	// ErrTooManyEffects indicates that there are too many effects
+	ErrTooManyEffects
+	// ErrBadEffectType indicates an invalid effect type
+	ErrBadEffectType
+	// ErrSchemaViolation indicates that the blocks do not match the schema of the item type
+	ErrSchemaViolation
+	// ErrBlockOrder indicates that blocks are out of canonical order or duplicated
+	ErrBlockOrder
+	// ErrBadCodepoint indicates a codepoint outside of the private use area encoding
+	ErrBadCodepoint
+	// ErrBadBlockData indicates that a block failed to decode for a reason not covered by another kind
+	ErrBadBlockData
+	// ErrLimitExceeded indicates that the input exceeds a configured decode resource limit
+	ErrLimitExceeded
+	// ErrTrailingBytes indicates that there are bytes after the end block
+	ErrTrailingBytes
+	// ErrMissingEndData indicates that the data ended without an end block
+	ErrMissingEndData
+	// ErrDuplicateBlock indicates that a block which may only appear once was found again
+	ErrDuplicateBlock
+	// ErrMissingIdentification indicates that an identification to encode is nil
+	ErrMissingIdentification
+	// ErrIdentificationOrder indicates that a pre-identified stat follows a rolled one
+	ErrIdentificationOrder
+	// ErrNonCanonical indicates data which decodes but would not encode back to the same bytes
+	ErrNonCanonical
 )
 
+// Error returns the message describing the error kind
+func (k ErrorKind) Error() string {
+	switch k {
+	case ErrUnexpectedEndOfBytes:
+		return "Unexpected end of bytes"
+	case ErrStartReparse:
+		return "Start block encountered during reparsing"
+	case ErrUnknownBlock:
+		return "Unknown block"
+	case ErrNoBasevalueGiven:
+		return "No base value given for stat"
+	case ErrTooManyIdentifications:
+		return "Too many identifications (maximum is 255)"
+	case ErrNoTypeGiven:
+		return "No type given while encoding TypeData"
+	case ErrNoNameGiven:
+		return "No name given while encoding NameData"
+	case ErrInvalidVarInt:
+		return "Invalid VarInt"
+	case ErrNoStartBlockFound:
+		return "No start block found"
+	case ErrUnknownVersion:
+		return "Unknown version"
+	case ErrBadItemType:
+		return "Invalid item type"
+	case ErrNonAsciiString:
+		return "String contains non-ASCII characters"
+	case ErrTooManyPowders:
+		return "Too many powders (maximum is 6)"
+	case ErrBadElement:
+		return "Invalid element"
+	case ErrBadPowderTier:
+		return "Invalid powder tier"
+	case ErrBadGearType:
+		return "Invalid gear type"
+	case ErrInvalidDurability:
+		return "Invalid durability"
+	case ErrBadClassType:
+		return "Invalid class type"
+	case ErrBadSkillType:
+		return "Invalid skill type"
+	case ErrInvalidSkillRequirement:
+		return "Invalid skill requirement"
+	case ErrBadAttackSpeed:
+		return "Invalid attack speed"
+	case ErrInvalidDamage:
+		return "Invalid damage"
+	case ErrBadConsumableType:
+		return "Invalid consumable type"
+	case ErrInvalidUses:
+		return "Invalid uses"
+	case ErrTooManyEffects:
+		return "Too many effects (maximum is 255)"
+	case ErrBadEffectType:
+		return "Invalid effect type"
+	case ErrSchemaViolation:
+		return "Schema violation"
+	case ErrBlockOrder:
+		return "Invalid block order"
+	case ErrBadCodepoint:
+		return "Invalid codepoint"
+	case ErrBadBlockData:
+		return "Invalid block data"
+	case ErrLimitExceeded:
+		return "Decode limit exceeded"
+	case ErrTrailingBytes:
+		return "Trailing bytes after end block"
+	case ErrMissingEndData:
+		return "Missing end block"
+	case ErrDuplicateBlock:
+		return "Duplicate block"
+	case ErrMissingIdentification:
+		return "Missing identification"
+	case ErrIdentificationOrder:
+		return "Identifications out of order"
+	case ErrNonCanonical:
+		return "Non-canonical encoding"
+	default:
+		return fmt.Sprintf("Unknown error kind: %d", int(k))
+	}
+}
+
 // DataBlockID is used for error reporting
 type DataBlockID byte
 
+// ValueError describes an offending value in detail
+type ValueError struct {
+	// Name describes the value
+	Name string
+	// Value is the offending value
+	Value int64
+	// Reason describes what is wrong with the value
+	Reason string
+}
+
+// Error returns the error message for an offending value
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("%s %d %s", e.Name, e.Value, e.Reason)
+}
+
+// LimitError describes a decode resource limit that was exceeded
+type LimitError struct {
+	// Limit is the name of the exceeded limit
+	Limit string
+	// Value is the size that was found or requested
+	Value int
+	// Max is the configured maximum
+	Max int
+}
+
+// Error returns the error message for an exceeded limit
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s %d exceeds the limit of %d", e.Limit, e.Value, e.Max)
+}
+
+// Unwrap returns ErrLimitExceeded, allowing the error to be matched with errors.Is
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
 // Encode errors
 // EncodeErrorType represents the type of an encoding error
-type EncodeErrorType int
+type EncodeErrorType = ErrorKind
 
 // EncodeError represents an error that occurred during encoding
 type EncodeError struct {
-	Type    EncodeErrorType
-	Details interface{}
+	// Type is the kind of the error
+	Type EncodeErrorType
+	// Err describes the offending value in detail, nil if there is nothing more to say
+	Err error
 }
 
 // Error returns the error message for an encoding error
 func (e *EncodeError) Error() string {
-	switch e.Type {
-	case ErrNoTypeGiven:
-		return "No type given while encoding TypeData"
-	case ErrNoNameGiven:
-		return "No name given while encoding NameData"
-	case ErrNoBasevalueGiven:
-		return fmt.Sprintf("No base value given for stat with kind %v", e.Details)
-	case ErrTooManyIdentifications:
-		return "Too many identifications (maximum is 255)"
-	case ErrNonAsciiString:
-		return "String contains non-ASCII characters"
-	case ErrTooManyPowders:
-		return "Too many powders (maximum is 6)"
-	case ErrBadElement:
-		return fmt.Sprintf("Invalid element: %v", e.Details)
-	case ErrBadPowderTier:
-		return fmt.Sprintf("Invalid powder tier: %v", e.Details)
-	case ErrBadItemType:
-		return fmt.Sprintf("Invalid item type: %v", e.Details)
-	default:
-		return fmt.Sprintf("Unknown encoding error: %d", e.Type)
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s", e.Type.Error(), e.Err.Error())
+	}
+	return e.Type.Error()
+}
+
+// Unwrap returns the error kind and the detailed error, allowing both to be matched with errors.Is and errors.As
+func (e *EncodeError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Type, e.Err}
 	}
+	return []error{e.Type}
 }
 
 // Decode errors
 // DecodeErrorType represents the type of a decoding error
-type DecodeErrorType int
+type DecodeErrorType = ErrorKind
 
 // DecodeError represents an error that occurred during decoding
 type DecodeError struct {
-	Type    DecodeErrorType
-	Details interface{}
+	// Type is the kind of the error
+	Type DecodeErrorType
+	// Err describes the offending value in detail, nil if there is nothing more to say
+	Err error
 }
 
 // Error returns the error message for a decoding error
 func (e *DecodeError) Error() string {
-	switch e.Type {
-	case ErrUnexpectedEndOfBytes:
-		return "Unexpected end of bytes"
-	case ErrStartReparse:
-		return "Start block encountered during reparsing"
-	case ErrUnknownBlock:
-		return fmt.Sprintf("Unknown block: %v", e.Details)
-	case ErrInvalidVarInt:
-		return fmt.Sprintf("Invalid VarInt: %v", e.Details)
-	case ErrNoStartBlockFound:
-		return "No start block found"
-	case ErrUnknownVersion:
-		return fmt.Sprintf("Unknown version: %v", e.Details)
-	case ErrBadItemType:
-		return fmt.Sprintf("Invalid item type: %v", e.Details)
-	case ErrBadElement:
-		return fmt.Sprintf("Invalid element: %v", e.Details)
-	case ErrBadPowderTier:
-		return fmt.Sprintf("Invalid powder tier: %v", e.Details)
-	default:
-		return fmt.Sprintf("Unknown decoding error: %d", e.Type)
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s", e.Type.Error(), e.Err.Error())
 	}
+	return e.Type.Error()
 }
 
-// DecoderError wraps a decode error with context about which block was being decoded
+// Unwrap returns the error kind and the detailed error, allowing both to be matched with errors.Is and errors.As
+func (e *DecodeError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Type, e.Err}
+	}
+	return []error{e.Type}
+}
+
+// DecoderError wraps a decode error with context about where in the ID string it happened
 type DecoderError struct {
+	// ErrorData is the underlying decode error
 	ErrorData *DecodeError
-	During    *DataBlockID
+	// During is the ID of the block being decoded, nil if the error did not happen within a block
+	During *DataBlockID
+	// Block is the name of the block being decoded, empty if the error did not happen within a block
+	Block string
+	// Offset is the byte offset within the decoded data at which decoding failed, -1 if not known
+	Offset int
+}
+
+// Codepoint returns the index of the codepoint in the ID string that holds the byte at which decoding failed,
+// -1 if not known
+func (e *DecoderError) Codepoint() int {
+	if e.Offset < 0 {
+		return -1
+	}
+	return CodepointIndex(e.Offset)
 }
 
 // Error returns the error message for a decoder error
 func (e *DecoderError) Error() string {
-	if e.During != nil {
-		return fmt.Sprintf("Error while decoding block %d: %s", *e.During, e.ErrorData.Error())
+	position := ""
+	if e.Offset >= 0 {
+		position = fmt.Sprintf(" at byte %d (codepoint %d)", e.Offset, e.Codepoint())
 	}
-	return e.ErrorData.Error()
+
+	if e.Block != "" {
+		return fmt.Sprintf("Error while decoding %s%s: %s", e.Block, position, e.ErrorData.Error())
+	}
+	return fmt.Sprintf("Error%s: %s", position, e.ErrorData.Error())
+}
+
+// Unwrap returns the underlying decode error
+func (e *DecoderError) Unwrap() error {
+	return e.ErrorData
 }
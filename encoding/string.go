@@ -1,6 +1,10 @@
 package encoding
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
 
 // Constants defining Unicode private use areas used for encoding
 const (
@@ -13,29 +17,60 @@ const (
 // BadCodepointError represents an error for an invalid codepoint during decoding
 type BadCodepointError struct {
 	Codepoint uint32
+	// Index is the index of the codepoint within the decoded string
+	Index int
 }
 
 // Error returns the error message for a bad codepoint
 func (e *BadCodepointError) Error() string {
-	return fmt.Sprintf("Invalid codepoint: %06X", e.Codepoint)
+	return fmt.Sprintf("Invalid codepoint: %06X at index %d", e.Codepoint, e.Index)
+}
+
+// Unwrap returns ErrBadCodepoint, allowing the error to be matched with errors.Is
+func (e *BadCodepointError) Unwrap() error {
+	return ErrBadCodepoint
+}
+
+// CodepointIndex returns the index of the codepoint holding the byte at the given offset.
+// Every codepoint holds two bytes, except for the last one which may hold a single byte.
+func CodepointIndex(offset int) int {
+	return offset / 2
 }
 
 // EncodeString encodes bytes into a string using the Wynntils byte encoding scheme
 // https://github.com/Wynntils/Wynntils/blob/main/common/src/main/java/com/wynntils/utils/EncodedByteBuffer.java#L87
 func EncodeString(data []byte) string {
-	out := make([]rune, 0, (len(data)+len(data)%2)*2)
+	var out strings.Builder
+	out.Grow(EncodedLength(data))
 
 	for i := 0; i < len(data); i += 2 {
-		if i+1 < len(data) {
-			// Two bytes available
-			out = append(out, EncodeChar(data[i], &data[i+1]))
-		} else {
-			// Only one byte available
-			out = append(out, EncodeChar(data[i], nil))
-		}
+		out.WriteRune(encodeCharAt(data, i))
+	}
+
+	return out.String()
+}
+
+// AppendString appends the UTF-8 encoded ID string of the given bytes to dst and returns the extended slice
+func AppendString(dst []byte, data []byte) []byte {
+	for i := 0; i < len(data); i += 2 {
+		dst = utf8.AppendRune(dst, encodeCharAt(data, i))
 	}
 
-	return string(out)
+	return dst
+}
+
+// encodeCharAt encodes the two bytes starting at the given index, or only one if it is the last byte
+func encodeCharAt(data []byte, i int) rune {
+	if i+1 < len(data) {
+		return EncodeChar(data[i], &data[i+1])
+	}
+	return EncodeChar(data[i], nil)
+}
+
+// EncodedLength returns the number of UTF-8 bytes in the ID string of the given bytes.
+// Every codepoint of the encoding lies outside of the basic multilingual plane and takes four bytes.
+func EncodedLength(data []byte) int {
+	return (len(data) + 1) / 2 * 4
 }
 
 // EncodeChar encodes one or two bytes into a single character using the private use area encoding
@@ -49,17 +84,27 @@ func EncodeChar(a byte, b *byte) rune {
 	}
 }
 
+// DecodedLength returns the maximum number of bytes the given ID string decodes to
+func DecodedLength(data string) int {
+	return utf8.RuneCountInString(data) * 2
+}
+
 // DecodeString decodes a Wynntils private area encoded string into bytes
 // https://github.com/Wynntils/Wynntils/blob/main/common/src/main/java/com/wynntils/utils/EncodedByteBuffer.java#L33
 func DecodeString(data string) ([]byte, error) {
-	out := make([]byte, 0, len(data)*2)
+	out := make([]byte, 0, DecodedLength(data))
 
+	index := 0
 	for _, c := range data {
 		bytes, err := DecodeChar(c)
 		if err != nil {
+			if badCodepoint, ok := err.(*BadCodepointError); ok {
+				badCodepoint.Index = index
+			}
 			return nil, err
 		}
 		out = append(out, bytes...)
+		index++
 	}
 
 	return out, nil
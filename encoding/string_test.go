@@ -28,6 +28,14 @@ func TestStringRoundTrip(t *testing.T) {
 		if !bytes.Equal(decoded, tc) {
 			t.Errorf("Bytes mismatch after round trip. Expected %v, got %v", tc, decoded)
 		}
+
+		if length := EncodedLength(tc); length != len(encoded) {
+			t.Errorf("Incorrect encoded length of %v. Expected %d, got %d", tc, len(encoded), length)
+		}
+
+		if appended := string(AppendString([]byte("x"), tc)); appended != "x"+encoded {
+			t.Errorf("Incorrect appended string of %v. Expected %q, got %q", tc, "x"+encoded, appended)
+		}
 	}
 }
 
@@ -70,3 +78,55 @@ func TestEncodeDecodeChar(t *testing.T) {
 func bytePtr(b byte) *byte {
 	return &b
 }
+
+func FuzzDecodeString(f *testing.F) {
+	f.Add("")
+	f.Add("not an id string")
+	f.Add(EncodeString([]byte{0, 0, 1, 0, 2, 65, 102, 116, 101, 114, 115, 104, 111, 99, 107, 0, 255}))
+	f.Add(EncodeString([]byte{255, 254, 255, 255, 7}))
+
+	f.Fuzz(func(t *testing.T, data string) {
+		decoded, err := DecodeString(data)
+		if err != nil {
+			return
+		}
+
+		if len(decoded) > DecodedLength(data) {
+			t.Errorf("Decoded %d bytes, more than the maximum of %d", len(decoded), DecodedLength(data))
+		}
+	})
+}
+
+func FuzzEncodeString(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0, 1, 2})
+	f.Add([]byte{255, 254, 255, 255, 255})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		decoded, err := DecodeString(EncodeString(data))
+		if err != nil {
+			t.Fatalf("Error decoding %v: %v", data, err)
+		}
+
+		if !bytes.Equal(decoded, data) {
+			t.Errorf("Bytes mismatch after round trip. Expected %v, got %v", data, decoded)
+		}
+	})
+}
+
+func BenchmarkEncodeString(b *testing.B) {
+	data := bytes.Repeat([]byte{0, 1, 2, 255, 254, 42}, 16)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		EncodeString(data)
+	}
+}
+
+func BenchmarkAppendString(b *testing.B) {
+	data := bytes.Repeat([]byte{0, 1, 2, 255, 254, 42}, 16)
+	out := make([]byte, 0, EncodedLength(data))
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		out = AppendString(out[:0], data)
+	}
+}
@@ -1,86 +1,187 @@
 package encoding
 
+import (
+	"fmt"
+	"math"
+)
+
 // EncodeVarInt encodes an integer of variable size into bytes using zigzag encoding
 // This format is used by wynntils for efficient integer representation
 func EncodeVarInt(value int64) []byte {
+	return AppendVarInt(make([]byte, 0, VarIntLength(value)), value)
+}
+
+// AppendVarInt appends the varint encoding of an integer to the given bytes and returns the extended slice
+func AppendVarInt(dst []byte, value int64) []byte {
 	// zigzag encoding to remove sign bit
 	zigzagged := uint64((value << 1) ^ (value >> 63))
 
-	// Calculate number of bytes needed (7 bits per byte)
-	// Highest bit is used to indicate end of encoding
+	// Highest bit is used to indicate that more bytes follow
+	for zigzagged >= 0x80 {
+		dst = append(dst, byte(zigzagged)|0x80)
+		zigzagged >>= 7
+	}
+
+	return append(dst, byte(zigzagged))
+}
+
+// VarIntLength returns the number of bytes in the varint encoding of an integer
+func VarIntLength(value int64) int {
+	zigzagged := uint64((value << 1) ^ (value >> 63))
+
+	// 7 bits per byte
 	numBytes := 1
-	temp := zigzagged >> 7
-	for temp != 0 {
+	for zigzagged >= 0x80 {
 		numBytes++
-		temp >>= 7
+		zigzagged >>= 7
 	}
 
-	outBytes := make([]byte, numBytes)
-	for i := 0; i < numBytes; i++ {
-		next := byte(zigzagged>>uint(7*i)) & 0x7F
-
-		// Set high bit for all bytes except the last one
-		if i < numBytes-1 {
-			next |= 0x80 // 10000000
-		}
+	return numBytes
+}
 
-		outBytes[i] = next
+// MaxVarIntLength is the maximum number of bytes in a canonical varint holding a 64 bit value
+const MaxVarIntLength = 10
+
+// VarIntProblem describes why a varint could not be decoded
+type VarIntProblem int
+
+const (
+	// VarIntTruncated indicates that the bytes ended before the last byte of the varint
+	VarIntTruncated VarIntProblem = iota
+	// VarIntOverflow indicates that the varint holds more than 64 bits
+	VarIntOverflow
+	// VarIntNonCanonical indicates that the varint is longer than needed for its value
+	VarIntNonCanonical
+	// VarIntOutOfRange indicates that the value does not fit the requested integer width
+	VarIntOutOfRange
+)
+
+// String returns the string representation of a VarIntProblem
+func (p VarIntProblem) String() string {
+	switch p {
+	case VarIntTruncated:
+		return "truncated"
+	case VarIntOverflow:
+		return "overflows 64 bits"
+	case VarIntNonCanonical:
+		return "not in canonical form"
+	case VarIntOutOfRange:
+		return "out of range"
+	default:
+		return fmt.Sprintf("unknown problem %d", int(p))
 	}
+}
 
-	return outBytes
+// VarIntError describes a varint that could not be decoded
+type VarIntError struct {
+	// Problem is what is wrong with the varint
+	Problem VarIntProblem
+	// Length is the number of bytes read before the problem was found
+	Length int
+	// Value is the decoded value for out of range varints
+	Value int64
 }
 
-// DecodeVarInt decodes a variable sized integer from a byte slice
-// Returns the decoded value, number of bytes consumed, and any error
-func DecodeVarInt(bytes []byte) (int64, int, error) {
-	if len(bytes) == 0 {
-		return 0, 0, &DecodeError{Type: ErrUnexpectedEndOfBytes}
+// Error returns the error message for a bad varint
+func (e *VarIntError) Error() string {
+	if e.Problem == VarIntOutOfRange {
+		return fmt.Sprintf("varint value %d is %s", e.Value, e.Problem)
 	}
+	return fmt.Sprintf("varint %s after %d bytes", e.Problem, e.Length)
+}
 
-	var value int64
-	var i int
+// Unwrap returns ErrUnexpectedEndOfBytes for truncated varints, allowing them to be matched with errors.Is
+func (e *VarIntError) Unwrap() error {
+	if e.Problem == VarIntTruncated {
+		return ErrUnexpectedEndOfBytes
+	}
+	return nil
+}
 
-	for i = 0; i < len(bytes); i++ {
-		b := bytes[i]
+// varIntError creates a decode error for a bad varint
+func varIntError(problem VarIntProblem, length int, value int64) *DecodeError {
+	return &DecodeError{
+		Type: ErrInvalidVarInt,
+		Err:  &VarIntError{Problem: problem, Length: length, Value: value},
+	}
+}
 
-		// Add the bits from this byte (sans continuation bit) to the value
-		value |= int64(b&0x7F) << uint(7*i)
+// DecodeVarInt decodes a variable sized integer from a byte slice.
+// Truncated, overflowing and non-canonical varints are rejected with ErrInvalidVarInt.
+// Returns the decoded value, number of bytes consumed, and any error
+func DecodeVarInt(bytes []byte) (int64, int, error) {
+	return DecodeVarIntMode(bytes, false)
+}
 
-		// If high bit is not set, this is the last byte
-		if (b & 0x80) == 0 {
-			i++ // include this byte in the count
-			break
+// DecodeVarIntMode decodes a variable sized integer from a byte slice.
+// In lenient mode only truncated varints are rejected, overlong encodings are accepted
+// and bits beyond 64 are ignored.
+func DecodeVarIntMode(bytes []byte, lenient bool) (int64, int, error) {
+	i := 0
+	return decodeVarInt(func() (byte, bool) {
+		if i >= len(bytes) {
+			return 0, false
 		}
+		i++
+		return bytes[i-1], true
+	}, lenient)
+}
+
+// DecodeVarInt32 decodes a variable sized integer which has to fit into 32 bits from a byte slice
+func DecodeVarInt32(bytes []byte, lenient bool) (int32, int, error) {
+	value, n, err := DecodeVarIntMode(bytes, lenient)
+	if err != nil {
+		return 0, n, err
 	}
 
-	// Convert from zigzag encoding back to signed
-	value = int64((uint64(value) >> 1)) ^ -(value & 1)
+	if value < math.MinInt32 || value > math.MaxInt32 {
+		return 0, n, varIntError(VarIntOutOfRange, n, value)
+	}
 
-	return value, i, nil
+	return int32(value), n, nil
 }
 
 // DecodeVarIntFromIterator decodes a variable-length encoded integer from a byte iterator
 func DecodeVarIntFromIterator(nextByte func() (byte, error)) (int64, error) {
-	var value int64
-	var data []byte
-
-	for {
+	value, _, err := decodeVarInt(func() (byte, bool) {
 		b, err := nextByte()
-		if err != nil {
-			return 0, &DecodeError{Type: ErrUnexpectedEndOfBytes}
+		return b, err == nil
+	}, false)
+	return value, err
+}
+
+// decodeVarInt decodes a zigzag encoded varint from the bytes returned by next,
+// which returns false once there are no more bytes
+func decodeVarInt(next func() (byte, bool), lenient bool) (int64, int, error) {
+	var value uint64
+
+	for n := 1; ; n++ {
+		b, ok := next()
+		if !ok {
+			return 0, n - 1, varIntError(VarIntTruncated, n-1, 0)
 		}
 
-		data = append(data, b)
+		if !lenient {
+			// The tenth byte holds the highest bit only
+			if n > MaxVarIntLength || (n == MaxVarIntLength && b > 1) {
+				return 0, n, varIntError(VarIntOverflow, n, 0)
+			}
 
-		if (b & 0b10000000) == 0 {
-			break
+			// A final zero group could have been left out
+			if n > 1 && b == 0 {
+				return 0, n, varIntError(VarIntNonCanonical, n, 0)
+			}
 		}
-	}
 
-	for i, b := range data {
-		value |= int64(b&0b01111111) << (7 * i)
-	}
+		// Add the bits from this byte (sans continuation bit) to the value
+		if n <= MaxVarIntLength {
+			value |= uint64(b&0x7F) << uint(7*(n-1))
+		}
 
-	// Convert from zigzag encoding back to signed integer
-	return (value >> 1) ^ -(value & 1), nil
+		// If high bit is not set, this is the last byte
+		if (b & 0x80) == 0 {
+			// Convert from zigzag encoding back to signed
+			return int64(value>>1) ^ -int64(value&1), n, nil
+		}
+	}
 }
@@ -1,6 +1,8 @@
 package encoding
 
 import (
+	"bytes"
+	"errors"
 	"testing"
 )
 
@@ -36,5 +38,116 @@ func TestVarIntRoundTrip(t *testing.T) {
 		if decoded != tc {
 			t.Errorf("Value mismatch after round trip. Expected %d, got %d", tc, decoded)
 		}
+
+		if length := VarIntLength(tc); length != len(bytes) {
+			t.Errorf("Incorrect length of %d. Expected %d, got %d", tc, len(bytes), length)
+		}
+
+		if appended := AppendVarInt([]byte{7}, tc); string(appended[1:]) != string(bytes) || appended[0] != 7 {
+			t.Errorf("Incorrect appended encoding of %d. Expected %v after 7, got %v", tc, bytes, appended)
+		}
+	}
+}
+
+func TestDecodeVarIntStrict(t *testing.T) {
+	testCases := []struct {
+		name    string
+		bytes   []byte
+		problem VarIntProblem
+		lenient bool
+	}{
+		{"empty", []byte{}, VarIntTruncated, false},
+		{"unterminated", []byte{0x80, 0x80}, VarIntTruncated, false},
+		{"non canonical", []byte{0x82, 0x00}, VarIntNonCanonical, true},
+		{"eleven bytes", []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x81, 0x00}, VarIntOverflow, true},
+		{"tenth byte overflow", []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x02}, VarIntOverflow, true},
+	}
+
+	for _, tc := range testCases {
+		_, _, err := DecodeVarInt(tc.bytes)
+
+		var varIntErr *VarIntError
+		if !errors.Is(err, ErrInvalidVarInt) || !errors.As(err, &varIntErr) {
+			t.Errorf("%s: expected %v, got %v", tc.name, ErrInvalidVarInt, err)
+			continue
+		}
+		if varIntErr.Problem != tc.problem {
+			t.Errorf("%s: expected problem %v, got %v", tc.name, tc.problem, varIntErr.Problem)
+		}
+
+		_, n, err := DecodeVarIntMode(tc.bytes, true)
+		if tc.lenient && (err != nil || n != len(tc.bytes)) {
+			t.Errorf("%s: expected lenient mode to read %d bytes, got %d: %v", tc.name, len(tc.bytes), n, err)
+		}
+	}
+
+	if _, _, err := DecodeVarInt([]byte{0x80}); !errors.Is(err, ErrUnexpectedEndOfBytes) {
+		t.Errorf("Expected truncated varint to match %v, got %v", ErrUnexpectedEndOfBytes, err)
+	}
+}
+
+func TestDecodeVarInt32(t *testing.T) {
+	testCases := []struct {
+		value int64
+		valid bool
+	}{
+		{0, true},
+		{-2147483648, true},
+		{2147483647, true},
+		{2147483648, false},
+		{-2147483649, false},
 	}
+
+	for _, tc := range testCases {
+		decoded, _, err := DecodeVarInt32(EncodeVarInt(tc.value), false)
+		if tc.valid && (err != nil || int64(decoded) != tc.value) {
+			t.Errorf("Expected %d, got %d: %v", tc.value, decoded, err)
+		}
+		if !tc.valid && !errors.Is(err, ErrInvalidVarInt) {
+			t.Errorf("Expected %v for %d, got %v", ErrInvalidVarInt, tc.value, err)
+		}
+	}
+}
+
+func TestDecodeVarIntFromIterator(t *testing.T) {
+	for _, value := range []int64{0, -1, 300, -9223372036854775808} {
+		bytes := EncodeVarInt(value)
+		decoded, err := DecodeVarIntFromIterator(func() (byte, error) {
+			if len(bytes) == 0 {
+				return 0, ErrUnexpectedEndOfBytes
+			}
+			b := bytes[0]
+			bytes = bytes[1:]
+			return b, nil
+		})
+
+		if err != nil || decoded != value {
+			t.Errorf("Expected %d, got %d: %v", value, decoded, err)
+		}
+	}
+}
+
+func FuzzDecodeVarInt(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0x80})
+	f.Add([]byte{0x82, 0x00})
+	f.Add(EncodeVarInt(-9223372036854775808))
+	f.Add([]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		value, n, err := DecodeVarInt(data)
+
+		if _, lenientN, lenientErr := DecodeVarIntMode(data, true); err == nil && (lenientErr != nil || lenientN != n) {
+			t.Errorf("Lenient decoding rejected strictly valid varint %v: %v", data, lenientErr)
+		}
+
+		if err != nil {
+			return
+		}
+
+		// Strict decoding only accepts the canonical encoding of a value
+		if encoded := EncodeVarInt(value); !bytes.Equal(encoded, data[:n]) {
+			t.Errorf("Decoded %v to %d which encodes to %v", data[:n], value, encoded)
+		}
+	})
 }
@@ -2,7 +2,11 @@
 package idmangler
 
 import (
+	"fmt"
+	"io"
+
 	"github.com/AevtJJ/idmangler/block"
+	"github.com/AevtJJ/idmangler/encoding"
 	"github.com/AevtJJ/idmangler/item"
 	"github.com/AevtJJ/idmangler/types"
 )
@@ -10,25 +14,58 @@ import (
 // Version represents the current version of the idmangler library
 const Version = "0.1.0"
 
-// EncodeItem encodes an item represented as a series of blocks into an ID string
+// EncodeItem encodes an item represented as a series of blocks into an ID string.
+// The blocks must match the schema of their item type.
 func EncodeItem(blocks []block.AnyBlock) (string, error) {
 	encoder := block.NewItemEncoder()
 	return encoder.EncodeBlocks(blocks)
 }
 
-// DecodeItem decodes an ID string into a series of blocks
+// EncodeStream returns an encoder which writes the ID strings of items to the given writer, reusing its buffers
+func EncodeStream(writer io.Writer) *block.StreamEncoder {
+	return block.NewStreamEncoder(writer, nil)
+}
+
+// DecodeItem decodes an ID string into a series of blocks.
+// The blocks must match the schema of their item type.
 func DecodeItem(idString string) ([]block.AnyBlock, error) {
 	decoder := block.NewItemDecoder()
 	return decoder.DecodeString(idString)
 }
 
-// EncodeItemObject encodes an Item object into an ID string
+// DecodeItemWithOptions decodes an ID string into a series of blocks within the given resource limits
+func DecodeItemWithOptions(idString string, options block.DecodeOptions) ([]block.AnyBlock, error) {
+	decoder := block.NewItemDecoder().WithOptions(options)
+	return decoder.DecodeString(idString)
+}
+
+// DecodeItemStrict decodes an ID string into a series of blocks, rejecting trailing bytes,
+// a missing EndData block and duplicated singleton blocks
+func DecodeItemStrict(idString string) ([]block.AnyBlock, error) {
+	decoder := block.NewItemDecoder().WithStrict(true)
+	return decoder.DecodeString(idString)
+}
+
+// VisitItem decodes an ID string and passes each block to the visitor as soon as it is decoded
+func VisitItem(idString string, visitor block.BlockVisitor) error {
+	decoder := block.NewItemDecoder()
+	return decoder.Visit(idString, visitor)
+}
+
+// DecodeStream returns a decoder for the items of a stream, such as a chat log, one item at a time
+func DecodeStream(reader io.RuneReader) *block.StreamDecoder {
+	return block.NewStreamDecoder(reader, nil)
+}
+
+// EncodeItemObject encodes an Item object into an ID string.
+// Crafted gear and consumables are encoded from their CraftedGear and CraftedConsumable properties.
 func EncodeItemObject(item *item.Item) (string, error) {
 	blocks := item.ToBlocks()
 	return EncodeItem(blocks)
 }
 
-// DecodeItemObject decodes an ID string into an Item object
+// DecodeItemObject decodes an ID string into an Item object.
+// The CraftedGear or CraftedConsumable properties are set depending on the decoded item type.
 func DecodeItemObject(idString string) (*item.Item, error) {
 	blocks, err := DecodeItem(idString)
 	if err != nil {
@@ -38,6 +75,73 @@ func DecodeItemObject(idString string) (*item.Item, error) {
 	return item.FromBlocks(blocks)
 }
 
+// DecodeItemObjectLenient decodes as much of an ID string as possible into an Item object.
+// The returned item is built from every block decoded before a failure and is nil only if
+// not even the start of the item could be decoded. The result describes any failure and warnings.
+func DecodeItemObjectLenient(idString string) (*item.Item, *block.DecodeResult) {
+	result := block.NewItemDecoder().DecodeStringLenient(idString)
+
+	partial, err := item.FromBlocks(result.Blocks)
+	if err != nil {
+		return nil, result
+	}
+
+	return partial, result
+}
+
+// Inspect decodes an ID string into a trace of every block and field with the bytes and codepoints they came from
+func Inspect(idString string) *block.Trace {
+	return block.Inspect(idString)
+}
+
+// RoundTripError describes an ID string which does not encode back to itself after decoding
+type RoundTripError struct {
+	// Original is the verified ID string
+	Original string
+	// Reencoded is the ID string produced by encoding the decoded blocks
+	Reencoded string
+	// Offset is the first byte offset at which the decoded data of the strings differs
+	Offset int
+}
+
+// Error returns the error message for a failed round trip
+func (e *RoundTripError) Error() string {
+	return fmt.Sprintf("ID string changes at byte %d (codepoint %d) when re-encoded", e.Offset, encoding.CodepointIndex(e.Offset))
+}
+
+// Verify checks that decoding the ID string into blocks and encoding them again reproduces
+// the identical string. Returns the decoding or encoding error, or a *RoundTripError if the strings differ.
+func Verify(idString string) error {
+	blocks, err := DecodeItem(idString)
+	if err != nil {
+		return err
+	}
+
+	reencoded, err := EncodeItem(blocks)
+	if err != nil {
+		return err
+	}
+
+	if reencoded == idString {
+		return nil
+	}
+
+	// Both strings have been decoded successfully at this point
+	original, _ := encoding.DecodeString(idString)
+	encoded, _ := encoding.DecodeString(reencoded)
+
+	offset := 0
+	for offset < len(original) && offset < len(encoded) && original[offset] == encoded[offset] {
+		offset++
+	}
+
+	return &RoundTripError{
+		Original:  idString,
+		Reencoded: reencoded,
+		Offset:    offset,
+	}
+}
+
 // CreateBasicItem creates a basic item with the minimum required blocks
 func CreateBasicItem(name string, itemType types.ItemType) []block.AnyBlock {
 	startBlock := block.NewStartData(types.Version1)
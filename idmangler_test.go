@@ -0,0 +1,145 @@
+package idmangler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AevtJJ/idmangler/block"
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/internal/fixtures"
+	"github.com/AevtJJ/idmangler/item"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+// aftershock is a real gear item with the legacy version byte 0
+var aftershock = fixtures.Aftershock()
+
+func TestVerify(t *testing.T) {
+	testCases := []struct {
+		name   string
+		bytes  []byte
+		valid  bool
+		offset int
+	}{
+		{"real item", aftershock, true, 0},
+		{"extended identifications", []byte{0, 1, 1, 0, 2, 'A', 0, 3, 1, 1, 1, 2, 4, 10, 6, 50, 255}, true, 0},
+		{"trailing bytes", append(append([]byte{}, aftershock...), 7), false, 38},
+		{"missing end", aftershock[:len(aftershock)-1], false, 37},
+	}
+
+	for _, tc := range testCases {
+		err := Verify(encoding.EncodeString(tc.bytes))
+
+		if tc.valid {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tc.name, err)
+			}
+			continue
+		}
+
+		var roundTripErr *RoundTripError
+		if !errors.As(err, &roundTripErr) || roundTripErr.Offset != tc.offset {
+			t.Errorf("%s: expected a round trip error at byte %d, got %v", tc.name, tc.offset, err)
+		}
+	}
+}
+
+func TestIdentificationOrder(t *testing.T) {
+	built := item.NewBasicItem("A", types.Gear)
+	built.AddIdentification(1, 10, 50)
+	built.AddPreIdentifiedStat(2, 20)
+	built.AddIdentification(3, 30, 60)
+	built.AddPreIdentifiedStat(4, 40)
+
+	idString, err := EncodeItemObject(built)
+	if err != nil {
+		t.Fatalf("Error encoding item: %v", err)
+	}
+
+	if err := Verify(idString); err != nil {
+		t.Errorf("Expected the encoded item to verify, got %v", err)
+	}
+
+	decoded, err := DecodeItemObject(idString)
+	if err != nil {
+		t.Fatalf("Error decoding item: %v", err)
+	}
+
+	expected := []byte{2, 4, 1, 3}
+	for i, stat := range decoded.Identifications {
+		if stat.Kind != expected[i] || built.Identifications[i].Kind != expected[i] {
+			t.Errorf("Expected stat %d at index %d, got %d decoded and %d built", expected[i], i, stat.Kind, built.Identifications[i].Kind)
+		}
+	}
+
+	stats := []*types.Stat{built.Identifications[2], built.Identifications[0], built.Identifications[1]}
+	block.SortIdentifications(stats)
+	if stats[0].Kind != 2 || stats[1].Kind != 4 || stats[2].Kind != 1 {
+		t.Errorf("Expected pre-identified stats to be sorted first, got %d %d %d", stats[0].Kind, stats[1].Kind, stats[2].Kind)
+	}
+
+	// Identifications set out of order are rejected instead of being reordered on the way
+	built.Identifications = []*types.Stat{built.Identifications[2], built.Identifications[0]}
+	if _, err := EncodeItemObject(built); !errors.Is(err, encoding.ErrIdentificationOrder) {
+		t.Errorf("Expected %v, got %v", encoding.ErrIdentificationOrder, err)
+	}
+}
+
+func TestDecodeItemObjectLenientDuplicates(t *testing.T) {
+	// A repeated NameData block breaks the schema, the last name wins in the partial item
+	idString := encoding.EncodeString([]byte{0, 1, 1, 0, 2, 'A', 0, 2, 'B', 0, 255})
+
+	decoded, result := DecodeItemObjectLenient(idString)
+	if decoded == nil || decoded.Name != "B" {
+		t.Fatalf("Expected a partial item named B, got %+v (%v)", decoded, result.Err)
+	}
+}
+
+func FuzzDecodeItem(f *testing.F) {
+	f.Add(encoding.EncodeString(aftershock))
+	f.Add("")
+	f.Add("not an id string")
+
+	gear := item.NewCraftedGearItem("Crafted Spear", types.Spear, 120)
+	gear.CraftedGear.Requirements.Level = 60
+	gear.CraftedGear.AddIdentification(3, 42)
+	neutral := types.NewElementalRange(10, 20)
+	gear.CraftedGear.Damage = &item.Damage{
+		AttackSpeed: types.Fast,
+		Neutral:     &neutral,
+		Elemental:   map[types.Element]types.ElementalRange{types.Fire: types.NewElementalRange(5, 9), types.Air: types.NewElementalRange(1, 2)},
+	}
+	gear.CraftedGear.Defense = &item.Defense{Health: -120, Defenses: map[types.Element]int32{types.Earth: 3, types.Water: -15}}
+	gear.Powders = []types.Powder{{Element: types.Earth, Tier: 6}, {Element: types.Air, Tier: 3}, {Element: types.Fire, Tier: 1}}
+	gear.PowderSlots = 4
+	potion := item.NewCraftedConsumableItem("Crafted Potion", types.Potion, 3)
+	potion.CraftedConsumable.AddEffect(types.Heal, 420)
+	for _, seed := range []*item.Item{gear, potion} {
+		idString, err := EncodeItemObject(seed)
+		if err != nil {
+			f.Fatalf("Error encoding seed: %v", err)
+		}
+		f.Add(idString)
+	}
+
+	f.Fuzz(func(t *testing.T, idString string) {
+		blocks, err := DecodeItem(idString)
+		if err == nil {
+			if decoded, err := item.FromBlocks(blocks); err == nil {
+				_, _ = EncodeItemObject(decoded)
+			}
+		}
+
+		// Every string the strict decoder accepts has to encode back to itself.
+		// Trailing bytes and a missing EndData block are dropped by the default decoder.
+		if _, err := DecodeItemStrict(idString); err == nil {
+			if err := Verify(idString); err != nil {
+				t.Errorf("Expected %q to verify, got %v", idString, err)
+			}
+		}
+
+		if decoded, _ := DecodeItemObjectLenient(idString); decoded != nil {
+			_, _ = EncodeItemObject(decoded)
+		}
+	})
+}
@@ -0,0 +1,11 @@
+// Package fixtures holds encoded items shared by the tests of several packages
+package fixtures
+
+// Aftershock returns the data of a real gear item written with the legacy version byte 0.
+// Every call returns a new copy, so tests can modify it freely.
+func Aftershock() []byte {
+	return []byte{
+		0, 0, 1, 0, 2, 65, 102, 116, 101, 114, 115, 104, 111, 99, 107, 0, 3, 5, 0, 56, 93, 9, 108,
+		72, 83, 10, 83, 3, 124, 4, 4, 4, 49, 140, 96, 5, 2, 255,
+	}
+}
@@ -0,0 +1,94 @@
+package item
+
+import (
+	"github.com/AevtJJ/idmangler/block"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+// CraftedConsumableItem holds the properties specific to crafted consumables such as potions, food and scrolls
+type CraftedConsumableItem struct {
+	// ConsumableType is the type of the consumable
+	ConsumableType types.ConsumableType
+	// CurrentUses is the number of uses remaining
+	CurrentUses byte
+	// MaxUses is the maximum number of uses
+	MaxUses byte
+	// Effects is a list of effects applied when the consumable is used
+	Effects []types.Effect
+	// Requirements are the requirements needed to use the consumable
+	Requirements Requirements
+	// Identifications is a list of stats granted by the consumable, nil if it has no identification block
+	Identifications []*types.CraftedStat
+}
+
+// NewCraftedConsumableItem creates a new crafted consumable item with the given name, type and maximum uses
+func NewCraftedConsumableItem(name string, consumableType types.ConsumableType, uses byte) *Item {
+	item := NewBasicItem(name, types.CraftedConsu)
+	item.CraftedConsumable = &CraftedConsumableItem{
+		ConsumableType: consumableType,
+		CurrentUses:    uses,
+		MaxUses:        uses,
+		Effects:        make([]types.Effect, 0),
+		Requirements: Requirements{
+			Skills: make([]types.SkillRequirement, 0),
+		},
+	}
+	return item
+}
+
+// AddEffect adds an effect to the consumable
+func (c *CraftedConsumableItem) AddEffect(kind types.EffectType, value int32) {
+	c.Effects = append(c.Effects, types.Effect{Kind: kind, Value: value})
+}
+
+// AddIdentification adds a crafted identification stat to the consumable
+func (c *CraftedConsumableItem) AddIdentification(kind byte, max int32) {
+	c.Identifications = append(c.Identifications, types.NewCraftedStat(kind, max))
+}
+
+// Use consumes a single charge of the consumable.
+// Returns false if there are no uses remaining.
+func (c *CraftedConsumableItem) Use() bool {
+	if c.CurrentUses == 0 {
+		return false
+	}
+	c.CurrentUses--
+	return true
+}
+
+// toBlocks converts the crafted consumable properties to a slice of blocks
+func (c *CraftedConsumableItem) toBlocks() []block.AnyBlock {
+	blocks := []block.AnyBlock{
+		block.NewCraftedConsumableTypeData(c.ConsumableType),
+		block.NewUsesData(c.CurrentUses, c.MaxUses),
+		block.NewEffectsData(c.Effects),
+		c.Requirements.toBlock(),
+	}
+
+	if c.Identifications != nil {
+		blocks = append(blocks, block.NewCraftedIdentificationData(c.Identifications))
+	}
+
+	return blocks
+}
+
+// applyBlock sets the crafted consumable properties stored in the given block
+func (c *CraftedConsumableItem) applyBlock(b block.AnyBlock) {
+	switch b := b.(type) {
+	case *block.CraftedConsumableTypeData:
+		c.ConsumableType = b.ConsumableType
+
+	case *block.UsesData:
+		c.CurrentUses = b.Current
+		c.MaxUses = b.Max
+
+	case *block.EffectsData:
+		c.Effects = b.Effects
+
+	case *block.RequirementsData:
+		c.Requirements = requirementsFromBlock(b)
+
+	case *block.CraftedIdentificationData:
+		c.Identifications = b.Identifications
+	}
+}
@@ -0,0 +1,41 @@
+package item
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/AevtJJ/idmangler/types"
+)
+
+func TestCraftedConsumableRoundTrip(t *testing.T) {
+	potion := NewCraftedConsumableItem("Crafted Potion", types.Potion, 3)
+	potion.CraftedConsumable.AddEffect(types.Heal, 1200)
+	potion.CraftedConsumable.AddEffect(types.Duration, 60)
+	potion.CraftedConsumable.Requirements.Level = 90
+	potion.CraftedConsumable.AddIdentification(30, 15)
+	potion.CraftedConsumable.Use()
+
+	scroll := NewCraftedConsumableItem("", types.Scroll, 1)
+	scroll.CraftedConsumable.Requirements.Skills = append(scroll.CraftedConsumable.Requirements.Skills,
+		types.SkillRequirement{Skill: types.Intelligence, Points: 20})
+
+	for _, tc := range []*Item{potion, scroll} {
+		decoded, err := FromBlocks(tc.ToBlocks())
+		if err != nil {
+			t.Errorf("Error converting %+v from blocks: %v", tc, err)
+			continue
+		}
+
+		if decoded.CraftedGear != nil {
+			t.Errorf("Unexpected crafted gear properties on consumable: %+v", decoded.CraftedGear)
+		}
+
+		if !reflect.DeepEqual(decoded.CraftedConsumable, tc.CraftedConsumable) {
+			t.Errorf("Crafted consumable mismatch after round trip. Expected %+v, got %+v", tc.CraftedConsumable, decoded.CraftedConsumable)
+		}
+
+		if decoded.Name != tc.Name || decoded.ItemType != types.CraftedConsu {
+			t.Errorf("Item mismatch after round trip. Expected %+v, got %+v", tc, decoded)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package item
+
+import (
+	"github.com/AevtJJ/idmangler/block"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+// CraftedGearItem holds the properties specific to crafted gear items
+type CraftedGearItem struct {
+	// GearType is the type of the crafted gear
+	GearType types.CraftedGearType
+	// EffectStrength is the effectiveness of the identifications in percent
+	EffectStrength byte
+	// MaxDurability is the maximum durability of the item
+	MaxDurability int32
+	// CurrentDurability is the current durability of the item
+	CurrentDurability int32
+	// Requirements are the requirements needed to use the item
+	Requirements Requirements
+	// Damage is the damage dealt by the item, nil if the item is not a weapon
+	Damage *Damage
+	// Defense is the health and elemental defenses of the item, nil if the item has none
+	Defense *Defense
+	// Identifications is a list of stats at full durability, nil if the item has no identification block
+	Identifications []*types.CraftedStat
+}
+
+// Requirements represents the requirements needed to use a crafted item
+type Requirements struct {
+	// Level is the required combat level
+	Level byte
+	// Class is the class the item is restricted to, nil if any class can use it
+	Class *types.ClassType
+	// Skills is a list of skill point requirements
+	Skills []types.SkillRequirement
+}
+
+// Damage represents the damage dealt by a crafted weapon
+type Damage struct {
+	// AttackSpeed is the attack speed of the weapon
+	AttackSpeed types.AttackSpeed
+	// Neutral is the neutral damage range, nil if the weapon deals no neutral damage
+	Neutral *types.ElementalRange
+	// Elemental contains the damage range for every element the weapon deals damage with
+	Elemental map[types.Element]types.ElementalRange
+}
+
+// Defense represents the health and elemental defenses of crafted armour or accessories
+type Defense struct {
+	// Health is the health bonus of the item
+	Health int32
+	// Defenses contains the defense value for every element the item has a defense in
+	Defenses map[types.Element]int32
+}
+
+// NewCraftedGearItem creates a new crafted gear item with the given name, gear type and durability
+func NewCraftedGearItem(name string, gearType types.CraftedGearType, durability int32) *Item {
+	item := NewBasicItem(name, types.CraftedGear)
+	item.CraftedGear = &CraftedGearItem{
+		GearType:          gearType,
+		EffectStrength:    100,
+		MaxDurability:     durability,
+		CurrentDurability: durability,
+		Requirements: Requirements{
+			Skills: make([]types.SkillRequirement, 0),
+		},
+	}
+	return item
+}
+
+// Effectiveness returns the effectiveness of the identifications as a fraction
+func (c *CraftedGearItem) Effectiveness() float64 {
+	return float64(c.EffectStrength) / 100
+}
+
+// AddIdentification adds a crafted identification stat with its value at full durability
+func (c *CraftedGearItem) AddIdentification(kind byte, max int32) {
+	c.Identifications = append(c.Identifications, types.NewCraftedStat(kind, max))
+}
+
+// toBlocks converts the crafted gear properties to a slice of blocks
+func (c *CraftedGearItem) toBlocks() []block.AnyBlock {
+	blocks := []block.AnyBlock{
+		block.NewCraftedGearTypeData(c.GearType),
+		block.NewDurabilityData(c.EffectStrength, c.MaxDurability, c.CurrentDurability),
+		c.Requirements.toBlock(),
+	}
+
+	if c.Damage != nil {
+		blocks = append(blocks, block.NewDamageData(c.Damage.AttackSpeed, c.Damage.Neutral, c.Damage.Elemental))
+	}
+
+	if c.Defense != nil {
+		blocks = append(blocks, block.NewDefenseData(c.Defense.Health, c.Defense.Defenses))
+	}
+
+	if c.Identifications != nil {
+		blocks = append(blocks, block.NewCraftedIdentificationData(c.Identifications))
+	}
+
+	return blocks
+}
+
+// applyBlock sets the crafted gear properties stored in the given block
+func (c *CraftedGearItem) applyBlock(b block.AnyBlock) {
+	switch b := b.(type) {
+	case *block.CraftedGearTypeData:
+		c.GearType = b.GearType
+
+	case *block.DurabilityData:
+		c.EffectStrength = b.EffectStrength
+		c.MaxDurability = b.Max
+		c.CurrentDurability = b.Current
+
+	case *block.RequirementsData:
+		c.Requirements = requirementsFromBlock(b)
+
+	case *block.DamageData:
+		c.Damage = &Damage{
+			AttackSpeed: b.AttackSpeed,
+			Neutral:     b.Neutral,
+			Elemental:   b.Elemental,
+		}
+
+	case *block.DefenseData:
+		c.Defense = &Defense{
+			Health:   b.Health,
+			Defenses: b.Defenses,
+		}
+
+	case *block.CraftedIdentificationData:
+		c.Identifications = b.Identifications
+	}
+}
+
+// toBlock converts the requirements to a RequirementsData block
+func (r *Requirements) toBlock() *block.RequirementsData {
+	return block.NewRequirementsData(r.Level, r.Class, r.Skills)
+}
+
+// requirementsFromBlock creates requirements from a RequirementsData block
+func requirementsFromBlock(b *block.RequirementsData) Requirements {
+	return Requirements{
+		Level:  b.Level,
+		Class:  b.Class,
+		Skills: b.Skills,
+	}
+}
@@ -0,0 +1,49 @@
+package item
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/AevtJJ/idmangler/types"
+)
+
+func TestCraftedGearRoundTrip(t *testing.T) {
+	class := types.Warrior
+	spear := NewCraftedGearItem("Crafted Spear", types.Spear, 120)
+	spear.CraftedGear.Requirements = Requirements{
+		Level:  75,
+		Class:  &class,
+		Skills: []types.SkillRequirement{{Skill: types.Strength, Points: 40}},
+	}
+	spear.CraftedGear.Damage = &Damage{
+		AttackSpeed: types.Slow,
+		Neutral:     &types.ElementalRange{Min: 10, Max: 20},
+		Elemental:   map[types.Element]types.ElementalRange{types.Earth: {Min: 30, Max: 45}},
+	}
+	spear.CraftedGear.AddIdentification(12, -8)
+	spear.SetPowderSlots(2)
+
+	helmet := NewCraftedGearItem("", types.Helmet, 80)
+	helmet.CraftedGear.CurrentDurability = 10
+	helmet.CraftedGear.EffectStrength = 54
+	helmet.CraftedGear.Defense = &Defense{
+		Health:   900,
+		Defenses: map[types.Element]int32{types.Water: -20},
+	}
+
+	for _, tc := range []*Item{spear, helmet} {
+		decoded, err := FromBlocks(tc.ToBlocks())
+		if err != nil {
+			t.Errorf("Error converting %+v from blocks: %v", tc, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(decoded.CraftedGear, tc.CraftedGear) {
+			t.Errorf("Crafted gear mismatch after round trip. Expected %+v, got %+v", tc.CraftedGear, decoded.CraftedGear)
+		}
+
+		if decoded.Name != tc.Name || decoded.PowderSlots != tc.PowderSlots {
+			t.Errorf("Item mismatch after round trip. Expected %+v, got %+v", tc, decoded)
+		}
+	}
+}
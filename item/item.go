@@ -8,6 +8,9 @@ import (
 
 // Item represents a generic Wynn item that can be encoded into and decoded from ID strings
 type Item struct {
+	// LegacyVersion is set if the StartData block holds the legacy version byte 0.
+	// Decoded items keep the version byte they were decoded with so that they re-encode to the same string.
+	LegacyVersion bool
 	// Name of the item
 	Name string
 	// ItemType is the type of the item
@@ -24,6 +27,12 @@ type Item struct {
 	Rerolls byte
 	// Extended encoding mode for identifications
 	ExtendedEncoding bool
+	// CraftedGear contains the properties of a crafted gear item, nil for other items
+	CraftedGear *CraftedGearItem
+	// CraftedConsumable contains the properties of a crafted consumable item, nil for other items
+	CraftedConsumable *CraftedConsumableItem
+	// Unknown holds an unknown block and all data after it verbatim, nil if every block was known
+	Unknown *block.RawTailBlock
 }
 
 // ShinyProp represents a shiny property on an item
@@ -60,16 +69,16 @@ func (i *Item) AddPowder(element int, tier int) (types.Powder, error) {
 	elem, err := types.ElementFromID(byte(element))
 	if err != nil {
 		return types.Powder{}, &encoding.EncodeError{
-			Type:    encoding.ErrBadElement,
-			Details: element,
+			Type: encoding.ErrBadElement,
+			Err:  err,
 		}
 	}
 
 	powder, err := types.NewPowder(elem, byte(tier))
 	if err != nil {
 		return types.Powder{}, &encoding.EncodeError{
-			Type:    encoding.ErrBadPowderTier,
-			Details: tier,
+			Type: encoding.ErrBadPowderTier,
+			Err:  err,
 		}
 	}
 
@@ -88,10 +97,21 @@ func (i *Item) AddIdentification(kind byte, baseValue int32, roll byte) {
 	i.Identifications = append(i.Identifications, types.NewStat(kind, basePtr, types.NewValueRoll(roll)))
 }
 
-// AddPreIdentifiedStat adds a pre-identified stat to the item
+// AddPreIdentifiedStat adds a pre-identified stat to the item.
+// The stat is placed after the other pre-identified stats and before the rolled ones,
+// which is the order the identifications are encoded and decoded in.
 func (i *Item) AddPreIdentifiedStat(kind byte, baseValue int32) {
 	basePtr := &baseValue
-	i.Identifications = append(i.Identifications, types.NewStat(kind, basePtr, types.NewPreIdentifiedRoll()))
+	stat := types.NewStat(kind, basePtr, types.NewPreIdentifiedRoll())
+
+	pos := 0
+	for pos < len(i.Identifications) && i.Identifications[pos].PreIdentified() {
+		pos++
+	}
+
+	i.Identifications = append(i.Identifications, nil)
+	copy(i.Identifications[pos+1:], i.Identifications[pos:])
+	i.Identifications[pos] = stat
 }
 
 // ToBlocks converts the item to a slice of blocks
@@ -99,15 +119,26 @@ func (i *Item) ToBlocks() []block.AnyBlock {
 	blocks := make([]block.AnyBlock, 0)
 
 	// Always start with StartData
-	startData := &block.StartData{Version: types.Version1}
+	startData := &block.StartData{Version: types.Version1, Legacy: i.LegacyVersion}
 	blocks = append(blocks, startData)
 
 	// Add TypeData
 	typeData := &block.TypeData{ItemType: i.ItemType}
 	blocks = append(blocks, typeData)
 
-	// Add NameData if name is present
-	if i.Name != "" {
+	// Add the blocks specific to crafted items
+	crafted := false
+	switch {
+	case i.ItemType == types.CraftedGear && i.CraftedGear != nil:
+		blocks = append(blocks, i.CraftedGear.toBlocks()...)
+		crafted = true
+	case i.ItemType == types.CraftedConsu && i.CraftedConsumable != nil:
+		blocks = append(blocks, i.CraftedConsumable.toBlocks()...)
+		crafted = true
+	}
+
+	// Add NameData if name is present, crafted items store it after their powders instead
+	if i.Name != "" && !crafted {
 		nameData := &block.NameData{Name: i.Name}
 		blocks = append(blocks, nameData)
 	}
@@ -130,6 +161,12 @@ func (i *Item) ToBlocks() []block.AnyBlock {
 		blocks = append(blocks, powderData)
 	}
 
+	// Add NameData for named crafted items
+	if i.Name != "" && crafted {
+		nameData := &block.NameData{Name: i.Name}
+		blocks = append(blocks, nameData)
+	}
+
 	// Add RerollData if the item has been rerolled
 	if i.Rerolls > 0 {
 		rerollData := &block.RerollData{Rerolls: i.Rerolls}
@@ -145,6 +182,12 @@ func (i *Item) ToBlocks() []block.AnyBlock {
 		blocks = append(blocks, shinyData)
 	}
 
+	// End with the unknown data if present, it already contains the original end
+	if i.Unknown != nil {
+		blocks = append(blocks, i.Unknown)
+		return blocks
+	}
+
 	// Always end with EndData
 	endData := &block.EndData{}
 	blocks = append(blocks, endData)
@@ -152,11 +195,13 @@ func (i *Item) ToBlocks() []block.AnyBlock {
 	return blocks
 }
 
-// FromBlocks creates an item from a slice of blocks
+// FromBlocks creates an item from a slice of blocks.
+// If a block which may appear only once repeats, the last one wins. Use FromBlocksStrict to reject it.
 func FromBlocks(blocks []block.AnyBlock) (*Item, error) {
 	if len(blocks) == 0 {
 		return nil, &encoding.DecoderError{
 			ErrorData: &encoding.DecodeError{Type: encoding.ErrNoStartBlockFound},
+			Offset:    -1,
 		}
 	}
 
@@ -172,7 +217,7 @@ func FromBlocks(blocks []block.AnyBlock) (*Item, error) {
 	for _, b := range blocks {
 		switch block := b.(type) {
 		case *block.StartData:
-			// Already handled by the decoder
+			item.LegacyVersion = block.Legacy
 
 		case *block.TypeData:
 			item.ItemType = block.ItemType
@@ -197,11 +242,57 @@ func FromBlocks(blocks []block.AnyBlock) (*Item, error) {
 				Value: block.Value,
 			})
 
+		case *block.RawTailBlock:
+			item.Unknown = block
+
 		case *block.EndData:
 			// End of data, stop processing
 			break
+
+		case *block.CraftedGearTypeData, *block.DurabilityData, *block.DamageData, *block.DefenseData:
+			item.craftedGear().applyBlock(b)
+
+		case *block.CraftedConsumableTypeData, *block.UsesData, *block.EffectsData:
+			item.craftedConsumable().applyBlock(b)
+
+		case *block.RequirementsData, *block.CraftedIdentificationData:
+			// Shared by crafted gear and consumables
+			if item.ItemType == types.CraftedConsu {
+				item.craftedConsumable().applyBlock(b)
+			} else {
+				item.craftedGear().applyBlock(b)
+			}
 		}
 	}
 
 	return item, nil
 }
+
+// FromBlocksStrict creates an item from a slice of blocks like FromBlocks, rejecting blocks which
+// may appear only once for the item type but repeat with ErrDuplicateBlock
+func FromBlocksStrict(blocks []block.AnyBlock) (*Item, error) {
+	if err := block.CheckDuplicates(blocks); err != nil {
+		return nil, &encoding.DecoderError{
+			ErrorData: &encoding.DecodeError{Type: encoding.ErrDuplicateBlock, Err: err},
+			Offset:    -1,
+		}
+	}
+
+	return FromBlocks(blocks)
+}
+
+// craftedGear returns the crafted gear properties of the item, creating them if needed
+func (i *Item) craftedGear() *CraftedGearItem {
+	if i.CraftedGear == nil {
+		i.CraftedGear = &CraftedGearItem{}
+	}
+	return i.CraftedGear
+}
+
+// craftedConsumable returns the crafted consumable properties of the item, creating them if needed
+func (i *Item) craftedConsumable() *CraftedConsumableItem {
+	if i.CraftedConsumable == nil {
+		i.CraftedConsumable = &CraftedConsumableItem{}
+	}
+	return i.CraftedConsumable
+}
@@ -0,0 +1,116 @@
+package item
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/AevtJJ/idmangler/block"
+	"github.com/AevtJJ/idmangler/encoding"
+	"github.com/AevtJJ/idmangler/internal/fixtures"
+	"github.com/AevtJJ/idmangler/types"
+)
+
+// aftershock is a real gear item with the legacy version byte 0
+var aftershock = fixtures.Aftershock()
+
+func TestVersionRoundTrip(t *testing.T) {
+	testCases := []struct {
+		legacy bool
+		bytes  []byte
+	}{
+		{true, aftershock},
+		{false, append([]byte{0, 1}, aftershock[2:]...)},
+	}
+
+	for _, tc := range testCases {
+		idString := encoding.EncodeString(tc.bytes)
+
+		blocks, err := block.NewItemDecoder().DecodeString(idString)
+		if err != nil {
+			t.Fatalf("Error decoding version byte %v item: %v", tc.bytes[1], err)
+		}
+
+		item, err := FromBlocks(blocks)
+		if err != nil {
+			t.Fatalf("Error converting version byte %v item: %v", tc.bytes[1], err)
+		}
+
+		if item.LegacyVersion != tc.legacy {
+			t.Errorf("Expected legacy version %v, got %v", tc.legacy, item.LegacyVersion)
+		}
+
+		encoded, err := block.NewItemEncoder().EncodeBlocks(item.ToBlocks())
+		if err != nil {
+			t.Fatalf("Error encoding version byte %v item: %v", tc.bytes[1], err)
+		}
+
+		if encoded != idString {
+			t.Errorf("Round trip of version byte %v changed the string. Expected %v, got %v", tc.bytes[1], tc.bytes, encoded)
+		}
+	}
+}
+
+func TestFromBlocksStrictDuplicates(t *testing.T) {
+	testCases := []struct {
+		name   string
+		blocks []block.AnyBlock
+		valid  bool
+	}{
+		{"single", []block.AnyBlock{block.NewStartData(types.Version1), block.NewTypeData(types.Gear), block.NewNameData("A"), block.NewRerollData(1)}, true},
+		{"duplicate rerolls", []block.AnyBlock{block.NewStartData(types.Version1), block.NewTypeData(types.Gear), block.NewRerollData(1), block.NewRerollData(2)}, false},
+		{"repeated shiny", []block.AnyBlock{block.NewStartData(types.Version1), block.NewTypeData(types.Gear), block.NewShinyData(1, 2), block.NewShinyData(3, 4)}, true},
+		{"duplicate name", []block.AnyBlock{block.NewStartData(types.Version1), block.NewNameData("A"), block.NewNameData("B")}, false},
+	}
+
+	for _, tc := range testCases {
+		if _, err := FromBlocks(tc.blocks); err != nil {
+			t.Errorf("%s: unexpected error without strict checks: %v", tc.name, err)
+		}
+
+		_, err := FromBlocksStrict(tc.blocks)
+		if tc.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+		if !tc.valid && !errors.Is(err, encoding.ErrDuplicateBlock) {
+			t.Errorf("%s: Expected %v, got %v", tc.name, encoding.ErrDuplicateBlock, err)
+		}
+	}
+}
+
+func TestFromBlocksEmpty(t *testing.T) {
+	_, err := FromBlocks(nil)
+
+	var decoderErr *encoding.DecoderError
+	if !errors.As(err, &decoderErr) || !errors.Is(err, encoding.ErrNoStartBlockFound) {
+		t.Fatalf("Expected %v, got %v", encoding.ErrNoStartBlockFound, err)
+	}
+
+	if decoderErr.Offset != -1 || strings.Contains(err.Error(), "at byte") {
+		t.Errorf("Expected no offset, got %d (%v)", decoderErr.Offset, err)
+	}
+}
+
+func TestShinyPropertiesRoundTrip(t *testing.T) {
+	built := NewBasicItem("A", types.Gear)
+	built.AddShinyProperty(1, 2)
+	built.AddShinyProperty(3, 4)
+
+	idString, err := block.NewItemEncoder().EncodeBlocks(built.ToBlocks())
+	if err != nil {
+		t.Fatalf("Error encoding item: %v", err)
+	}
+
+	blocks, err := block.NewItemDecoder().DecodeString(idString)
+	if err != nil {
+		t.Fatalf("Error decoding item: %v", err)
+	}
+	decoded, err := FromBlocks(blocks)
+	if err != nil {
+		t.Fatalf("Error converting item: %v", err)
+	}
+
+	if len(decoded.ShinyProps) != 2 || decoded.ShinyProps[1] != (ShinyProp{ID: 3, Value: 4}) {
+		t.Errorf("Expected both shiny properties, got %v", decoded.ShinyProps)
+	}
+}
@@ -0,0 +1,57 @@
+package types
+
+import (
+	"fmt"
+)
+
+// ClassType represents the possible classes an item can be restricted to
+type ClassType byte
+
+const (
+	// Mage class type
+	Mage ClassType = iota + 1
+	// Archer class type
+	Archer
+	// Warrior class type
+	Warrior
+	// Assassin class type
+	Assassin
+	// Shaman class type
+	Shaman
+)
+
+// String returns the string representation of a ClassType
+func (c ClassType) String() string {
+	switch c {
+	case Mage:
+		return "Mage"
+	case Archer:
+		return "Archer"
+	case Warrior:
+		return "Warrior"
+	case Assassin:
+		return "Assassin"
+	case Shaman:
+		return "Shaman"
+	default:
+		return fmt.Sprintf("Unknown(%d)", c)
+	}
+}
+
+// BadClassTypeError represents an error for an invalid class type ID
+type BadClassTypeError struct {
+	ID byte
+}
+
+// Error returns the error message for a bad class type
+func (e BadClassTypeError) Error() string {
+	return fmt.Sprintf("Invalid class type id: %d", e.ID)
+}
+
+// ClassTypeFromByte converts a byte to a ClassType or returns an error if invalid
+func ClassTypeFromByte(b byte) (ClassType, error) {
+	if b >= byte(Mage) && b <= byte(Shaman) {
+		return ClassType(b), nil
+	}
+	return 0, &BadClassTypeError{ID: b}
+}
@@ -0,0 +1,32 @@
+package types
+
+import (
+	"fmt"
+)
+
+// ElementalRange represents an inclusive range of values tied to an element,
+// such as the neutral or elemental damage dealt by a weapon
+type ElementalRange struct {
+	// Min is the lower bound of the range
+	Min int32
+	// Max is the upper bound of the range
+	Max int32
+}
+
+// String returns a string representation of the range
+func (r ElementalRange) String() string {
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
+
+// Valid checks whether the lower bound of the range does not exceed the upper bound
+func (r ElementalRange) Valid() bool {
+	return r.Min <= r.Max
+}
+
+// NewElementalRange creates a new ElementalRange with the specified bounds
+func NewElementalRange(min int32, max int32) ElementalRange {
+	return ElementalRange{
+		Min: min,
+		Max: max,
+	}
+}
@@ -0,0 +1,65 @@
+package types
+
+import (
+	"fmt"
+)
+
+// SkillType represents the skills skill points can be assigned to
+type SkillType byte
+
+const (
+	// Strength skill type
+	Strength SkillType = iota
+	// Dexterity skill type
+	Dexterity
+	// Intelligence skill type
+	Intelligence
+	// Defense skill type
+	Defense
+	// Agility skill type
+	Agility
+)
+
+// String returns the string representation of a SkillType
+func (s SkillType) String() string {
+	switch s {
+	case Strength:
+		return "Strength"
+	case Dexterity:
+		return "Dexterity"
+	case Intelligence:
+		return "Intelligence"
+	case Defense:
+		return "Defense"
+	case Agility:
+		return "Agility"
+	default:
+		return fmt.Sprintf("Unknown(%d)", s)
+	}
+}
+
+// BadSkillTypeError represents an error for an invalid skill type ID
+type BadSkillTypeError struct {
+	ID byte
+}
+
+// Error returns the error message for a bad skill type
+func (e BadSkillTypeError) Error() string {
+	return fmt.Sprintf("Invalid skill type id: %d", e.ID)
+}
+
+// SkillTypeFromByte converts a byte to a SkillType or returns an error if invalid
+func SkillTypeFromByte(b byte) (SkillType, error) {
+	if b <= byte(Agility) {
+		return SkillType(b), nil
+	}
+	return 0, &BadSkillTypeError{ID: b}
+}
+
+// SkillRequirement represents the amount of skill points required in a skill
+type SkillRequirement struct {
+	// Skill is the skill the requirement applies to
+	Skill SkillType
+	// Points is the amount of skill points required
+	Points int32
+}
@@ -1,5 +1,7 @@
 package types
 
+import "fmt"
+
 // Stat represents an identification stat in Wynncraft
 type Stat struct {
 	// Kind is the identifier of the stat
@@ -20,6 +22,18 @@ func (s *Stat) ContainsExtended() bool {
 	return s.Base != nil
 }
 
+// String returns a string representation of the stat
+func (s *Stat) String() string {
+	out := fmt.Sprintf("stat %d", s.Kind)
+	if s.Base != nil {
+		out += fmt.Sprintf(" base %d", *s.Base)
+	}
+	if s.PreIdentified() {
+		return out + " pre-identified"
+	}
+	return out + fmt.Sprintf(" roll %d", s.Roll.Value())
+}
+
 // CraftedStat represents an identification stat on a crafted item
 type CraftedStat struct {
 	// Kind is the identifier of the stat
@@ -20,12 +20,23 @@ func (v EncodingVersion) String() string {
 	}
 }
 
-// EncodingVersionFromByte converts a byte to an EncodingVersion or returns an error if invalid
+// BadVersionError represents an error for an unknown encoding version
+type BadVersionError struct {
+	Version byte
+}
+
+// Error returns the error message for a bad encoding version
+func (e BadVersionError) Error() string {
+	return fmt.Sprintf("Unknown encoding version: %d", e.Version)
+}
+
+// EncodingVersionFromByte converts a byte to an EncodingVersion or returns an error if invalid.
+// The legacy version byte 0 written by early Wynntils releases is laid out like Version1.
 func EncodingVersionFromByte(b byte) (EncodingVersion, error) {
 	switch b {
-	case 1, 0:
+	case 0, 1:
 		return Version1, nil
 	default:
-		return 0, fmt.Errorf("unknown encoding version: %d", b)
+		return 0, &BadVersionError{Version: b}
 	}
 }